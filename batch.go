@@ -0,0 +1,99 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// batchChunkSize is the number of coordinates handed to a worker at a time.
+const batchChunkSize = 256
+
+// BatchOptions holds the options for ReverseGeocodeBatch.
+type BatchOptions struct {
+	// Number of goroutines to use, defaults to runtime.GOMAXPROCS(0)
+	Workers int
+
+	// Context used to cancel the batch, defaults to context.Background()
+	Context context.Context
+}
+
+// ReverseGeocodeBatch runs ReverseGeocode on every coordinate in coords,
+// spreading the work across a pool of goroutines. The returned slices are the
+// same length as coords and in the same order, so the result for coords[i] is
+// in locs[i] and errs[i].
+//
+// If the context is cancelled any coordinates that haven't been processed yet
+// are given the context's error.
+func (r *Rgeo) ReverseGeocodeBatch(coords []geom.Coord, opts BatchOptions) ([]Location, []error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	locs := make([]Location, len(coords))
+	errs := make([]error, len(coords))
+
+	// Make sure the index is built before the workers start, otherwise they
+	// would all wait on the first one to build it anyway.
+	r.Build()
+
+	chunks := make(chan int)
+
+	var wg sync.WaitGroup
+
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			query := s2.NewContainsPointQuery(r.index, s2.VertexModelOpen)
+
+			for start := range chunks {
+				end := min(start+batchChunkSize, len(coords))
+
+				for i := start; i < end; i++ {
+					if err := ctx.Err(); err != nil {
+						errs[i] = err
+						continue
+					}
+
+					locs[i], errs[i] = r.reverseGeocode(query, coords[i])
+				}
+			}
+		}()
+	}
+
+	for start := 0; start < len(coords); start += batchChunkSize {
+		chunks <- start
+	}
+
+	close(chunks)
+	wg.Wait()
+
+	return locs, errs
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/twpayne/go-geom"
+)
+
+func TestReverseGeocodeBatch(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,52],[1,52],[1,53],[0,53],[0,52]]]}}
+			]
+		}`
+
+	r, err := New(func() []byte { return compressData(t, testgeo) })
+	if err != nil {
+		t.Error(err)
+	}
+
+	// Alternate between coordinates inside and outside the polygon so that
+	// any reordering shows up
+	coords := make([]geom.Coord, 1000)
+	for i := range coords {
+		if i%2 == 0 {
+			coords[i] = geom.Coord{0.5, 52.5}
+		} else {
+			coords[i] = geom.Coord{0, 0}
+		}
+	}
+
+	t.Run("ordered", func(t *testing.T) {
+		locs, errs := r.ReverseGeocodeBatch(coords, BatchOptions{Workers: 4})
+		if len(locs) != len(coords) || len(errs) != len(coords) {
+			t.Fatalf("expected %d results, got %d locations and %d errors",
+				len(coords), len(locs), len(errs))
+		}
+
+		for i := range coords {
+			expected, expectedErr := r.ReverseGeocode(coords[i])
+			if errs[i] != expectedErr {
+				t.Errorf("%d: expected error: %s\n got: %s\n", i, expectedErr, errs[i])
+			}
+			if diff := deep.Equal(expected, locs[i]); diff != nil {
+				t.Error(i, diff)
+			}
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, errs := r.ReverseGeocodeBatch(coords, BatchOptions{Context: ctx})
+		for i, err := range errs {
+			if err != context.Canceled {
+				t.Errorf("%d: expected error: %s\n got: %s\n", i, context.Canceled, err)
+			}
+		}
+	})
+}
+
+func BenchmarkReverseGeocodeBatch_10(b *testing.B) {
+	r, err := New(Countries10)
+	if err != nil {
+		b.Error(err)
+	}
+
+	coords := make([]geom.Coord, b.N)
+	for i := range coords {
+		coords[i] = geom.Coord{
+			(rand.Float64() * 360) - 180,
+			(rand.Float64() * 180) - 90,
+		}
+	}
+
+	r.Build()
+	b.ResetTimer()
+
+	_, _ = r.ReverseGeocodeBatch(coords, BatchOptions{})
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// Result is the return type for ReverseGeocodeDetailed.
+type Result struct {
+	// The same Location that ReverseGeocode would return
+	Location Location `json:"location"`
+
+	// Great circle distance to the nearest polygon boundary
+	BorderDistance s1.Angle `json:"border_distance"`
+
+	// Locations of the other polygons within the given tolerance, closest
+	// first
+	Candidates []Location `json:"candidates,omitempty"`
+}
+
+// Ambiguous reports whether there are any other candidate Locations near the
+// given coordinate, in which case the match should be treated with caution.
+func (r Result) Ambiguous() bool {
+	return len(r.Candidates) > 0
+}
+
+// ReverseGeocodeDetailed works like ReverseGeocode, but also reports how close
+// the coordinate is to a polygon boundary and which other polygons are within
+// tolerance of it. This is useful when the coordinates might be near a border,
+// where the lower resolution datasets aren't very reliable.
+//
+// If the coordinate isn't inside any of the polygons ErrLocationNotFound is
+// returned, but the Result will still hold the distance to the nearest
+// boundary and any candidates found.
+func (r *Rgeo) ReverseGeocodeDetailed(loc geom.Coord, tolerance s1.Angle) (Result, error) {
+	p := pointFromCoord(loc)
+
+	query := s2.NewContainsPointQuery(r.index, s2.VertexModelOpen)
+	containing := query.ContainingShapes(p)
+
+	var res Result
+
+	if nearest := r.closestEdges(p, s1.InfAngle(), 1); len(nearest) > 0 {
+		res.BorderDistance = nearest[0].Distance().Angle()
+	}
+
+	seen := make(map[s2.Shape]bool, len(containing))
+	for _, shape := range containing {
+		seen[shape] = true
+	}
+
+	for _, e := range r.closestEdges(p, tolerance, -1) {
+		shape := r.index.Shape(e.ShapeID())
+		if seen[shape] {
+			continue
+		}
+
+		seen[shape] = true
+		res.Candidates = append(res.Candidates, r.locs[shape].Location)
+	}
+
+	if len(containing) == 0 {
+		return res, ErrLocationNotFound
+	}
+
+	res.Location = r.combineLocations(containing)
+
+	return res, nil
+}
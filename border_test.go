@@ -0,0 +1,109 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/golang/geo/s1"
+)
+
+func TestReverseGeocodeDetailed(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TSU"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1,0],[2,0],[2,1],[1,1],[1,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name      string
+		in        []float64
+		tolerance s1.Angle
+		err       error
+		expected  Result
+	}{
+		{
+			name:      "clear",
+			in:        []float64{0.5, 0.5},
+			tolerance: s1.Degree / 10,
+			err:       nil,
+			expected: Result{
+				Location:       Location{CountryCode3: "TST"},
+				BorderDistance: s1.Degree / 2,
+			},
+		},
+		{
+			name:      "near border",
+			in:        []float64{0.9, 0.5},
+			tolerance: s1.Degree / 5,
+			err:       nil,
+			expected: Result{
+				Location:       Location{CountryCode3: "TST"},
+				BorderDistance: s1.Degree / 10,
+				Candidates:     []Location{{CountryCode3: "TSU"}},
+			},
+		},
+		{
+			name:      "outside",
+			in:        []float64{-0.1, 0.5},
+			tolerance: s1.Degree / 5,
+			err:       ErrLocationNotFound,
+			expected: Result{
+				BorderDistance: s1.Degree / 10,
+				Candidates:     []Location{{CountryCode3: "TST"}},
+			},
+		},
+	}
+
+	r, err := New(func() []byte { return compressData(t, testgeo) })
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			result, err := r.ReverseGeocodeDetailed(test.in, test.tolerance)
+			if err != test.err {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+			if math.Abs((result.BorderDistance - test.expected.BorderDistance).Degrees()) > 1e-3 {
+				t.Errorf("expected distance: %s\n got: %s\n",
+					test.expected.BorderDistance, result.BorderDistance)
+			}
+			if diff := deep.Equal(test.expected.Location, result.Location); diff != nil {
+				t.Error(diff)
+			}
+			if diff := deep.Equal(test.expected.Candidates, result.Candidates); diff != nil {
+				t.Error(diff)
+			}
+			if result.Ambiguous() != (len(test.expected.Candidates) > 0) {
+				t.Errorf("expected ambiguous: %t\n", len(test.expected.Candidates) > 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"sort"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+)
+
+// WithCellCache adds a lookup table of the S2 cells, down to the given level,
+// that are entirely inside the polygons without any boundaries crossing them.
+// Coordinates in those cells can then be reverse geocoded with a binary search
+// instead of a ContainsPointQuery, only falling back to the query near the
+// boundaries. This trades memory and a slower Build for faster lookups.
+//
+// Higher levels mean smaller cells, so more of each polygon is covered but the
+// table is bigger and slower to build. Level 10 cells are roughly 10km across,
+// see https://s2geometry.io/resources/s2cell_statistics for the others.
+func WithCellCache(level int) Option {
+	return func(o *options) {
+		o.cellLevel = min(max(level, 0), s2.MaxLevel)
+	}
+}
+
+// cellCache is a sorted list of non-overlapping cells, each with the
+// combined Location of the polygons containing it.
+type cellCache struct {
+	cells []s2.CellID
+	locs  []Location
+}
+
+// buildCellCache builds the cell cache at the given level.
+func (r *Rgeo) buildCellCache(level int) *cellCache {
+	coverer := &s2.RegionCoverer{
+		MinLevel: 0,
+		MaxLevel: level,
+		LevelMod: 1,
+		MaxCells: 1 << 30,
+	}
+
+	// Collect the cells that are inside at least one polygon
+	var candidates []s2.CellID
+
+	for id := 0; id < r.index.Len(); id++ {
+		p, ok := r.index.Shape(int32(id)).(*s2.Polygon)
+		if !ok {
+			continue
+		}
+
+		candidates = append(candidates, coverer.InteriorCovering(p)...)
+	}
+
+	// Sorting by the start of each cell's range puts parents before their
+	// children, so that the children can be skipped.
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].RangeMin(), candidates[j].RangeMin()
+		if a != b {
+			return a < b
+		}
+
+		return candidates[i].Level() < candidates[j].Level()
+	})
+
+	opts := s2.NewClosestEdgeQueryOptions().IncludeInteriors(false)
+	edges := s2.NewClosestEdgeQuery(r.index, opts)
+	contains := s2.NewContainsPointQuery(r.index, s2.VertexModelOpen)
+	touching := s1.ChordAngle(0).Successor()
+
+	c := new(cellCache)
+
+	for _, id := range candidates {
+		if n := len(c.cells); n > 0 && c.cells[n-1].Contains(id) {
+			continue
+		}
+
+		// Another polygon's boundary might cross this cell, in which case
+		// the answer isn't the same across the whole cell
+		cell := s2.CellFromCellID(id)
+		if edges.IsDistanceLess(s2.NewMinDistanceToCellTarget(cell), touching) {
+			continue
+		}
+
+		// With no boundaries in the cell, whatever contains its centre
+		// contains the whole cell
+		shapes := contains.ContainingShapes(cell.Center())
+
+		c.cells = append(c.cells, id)
+		c.locs = append(c.locs, r.combineLocations(shapes))
+	}
+
+	return c
+}
+
+// lookup returns the Location of the cached cell containing the given leaf
+// cell, if there is one.
+func (c *cellCache) lookup(leaf s2.CellID) (Location, bool) {
+	// Find the last cell starting at or before the leaf cell
+	i := sort.Search(len(c.cells), func(i int) bool {
+		return c.cells[i].RangeMin() > leaf
+	}) - 1
+
+	if i < 0 || !c.cells[i].Contains(leaf) {
+		return Location{}, false
+	}
+
+	return c.locs[i], true
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestWithCellCache(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[20,0],[20,20],[0,20],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"name_conve":"Testville"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[5,5],[10,5],[10,10],[5,10],[5,5]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		err      error
+		expected Location
+		cached   bool
+	}{
+		{
+			name:     "country",
+			in:       []float64{15, 15},
+			err:      nil,
+			expected: Location{CountryCode3: "TST"},
+			cached:   true,
+		},
+		{
+			name:     "city",
+			in:       []float64{7.5, 7.5},
+			err:      nil,
+			expected: Location{CountryCode3: "TST", City: "Testville"},
+			cached:   true,
+		},
+		{
+			name:     "city border",
+			in:       []float64{10.001, 7.5},
+			err:      nil,
+			expected: Location{CountryCode3: "TST"},
+			cached:   false,
+		},
+		{
+			name:     "out",
+			in:       []float64{-5, -5},
+			err:      ErrLocationNotFound,
+			expected: Location{},
+			cached:   false,
+		},
+	}
+
+	r, err := NewWithOptions(
+		WithReaders(strings.NewReader(testgeo)),
+		WithCellCache(8),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Build()
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			result, err := r.ReverseGeocode(test.in)
+			if err != test.err {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+			if diff := deep.Equal(test.expected, result); diff != nil {
+				t.Error(diff)
+			}
+
+			if _, ok := r.cache.lookup(cellIDFromCoord(test.in)); ok != test.cached {
+				t.Errorf("expected cached: %t\n", test.cached)
+			}
+		})
+	}
+}
+
+func TestWithCellCache_Countries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test (cell cache) in short mode")
+	}
+
+	plain, err := New(Countries110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cached, err := NewWithOptions(WithDatasets(Countries110), WithCellCache(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10000; i++ {
+		in := []float64{
+			(rand.Float64() * 360) - 180,
+			(rand.Float64() * 180) - 90,
+		}
+
+		expected, expectedErr := plain.ReverseGeocode(in)
+		result, err := cached.ReverseGeocode(in)
+		if err != expectedErr {
+			t.Errorf("%v: expected error: %s\n got: %s\n", in, expectedErr, err)
+		}
+		if diff := deep.Equal(expected, result); diff != nil {
+			t.Error(in, diff)
+		}
+	}
+}
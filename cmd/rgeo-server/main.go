@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+/*
+Command rgeo-server serves reverse geocoding over HTTP, see the rgeohttp
+package for the requests it answers.
+
+Usage
+
+	go install github.com/sams96/rgeo/cmd/rgeo-server@latest
+	rgeo-server -addr :8080 -datasets Provinces10,Cities10
+	curl 'localhost:8080/reverse?lat=51.5&lon=-0.12'
+
+The datasets are chosen with -datasets, from Countries110, Countries10,
+Provinces10 and Cities10, or a snapshot written by datagen -snapshot or
+rgeo.Save can be loaded with -snapshot instead, which skips parsing the GeoJSON
+but still has to build the index before the server is ready. -cellcache sets
+the level of rgeo.WithCellCache, which makes lookups faster at the cost of
+memory and a slower start.
+
+With -nominatim, GET /reverse answers in the same format as Nominatim's reverse
+API, so that clients of a Nominatim server can use rgeo-server instead for
+coarse lookups.
+
+/readyz only reports ready once the index has been built. The server shuts
+down gracefully on SIGINT or SIGTERM.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sams96/rgeo"
+	"github.com/sams96/rgeo/rgeohttp"
+)
+
+func main() {
+	// Read args
+	addrFlag := flag.String("addr", ":8080", "Address to listen on")
+	datasetsFlag := flag.String("datasets", "Provinces10,Cities10", "Comma separated datasets to use")
+	snapshotFileName := flag.String("snapshot", "", "Snapshot to load instead of the datasets")
+	cellCacheFlag := flag.Int("cellcache", 0, "Level of the cell cache, 0 for none")
+	nominatimFlag := flag.Bool("nominatim", false, "Answer GET /reverse in the same format as Nominatim")
+
+	flag.Parse()
+
+	r, err := load(*datasetsFlag, *snapshotFileName, *cellCacheFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var opts []rgeohttp.Option
+	if *nominatimFlag {
+		opts = append(opts, rgeohttp.WithNominatim())
+	}
+
+	srv := &http.Server{
+		Addr:              *addrFlag,
+		Handler:           rgeohttp.NewHandler(r, opts...),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Closed once the in-flight requests have finished after a signal
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		<-ctx.Done()
+
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := srv.Shutdown(shutdown); err != nil {
+			log.Print(err)
+		}
+	}()
+
+	log.Printf("listening on %s", *addrFlag)
+
+	// ListenAndServe returns as soon as Shutdown is called, without waiting
+	// for the requests to finish
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+
+	<-done
+}
+
+// load returns the Rgeo from the snapshot if there is one, or the datasets
+// otherwise.
+func load(names, snapshot string, cellLevel int) (*rgeo.Rgeo, error) {
+	if snapshot != "" {
+		f, err := os.Open(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return rgeo.Load(f, rgeo.WithCellCache(cellLevel))
+	}
+
+	var sets []func() []byte
+
+	for _, name := range strings.Split(names, ",") {
+		d, ok := rgeo.DatasetByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown dataset %q", name)
+		}
+
+		sets = append(sets, d)
+	}
+
+	return rgeo.NewWithOptions(rgeo.WithDatasets(sets...), rgeo.WithCellCache(cellLevel))
+}
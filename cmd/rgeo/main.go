@@ -0,0 +1,494 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+/*
+Command rgeo reverse geocodes the coordinates in CSV, TSV or JSON Lines files,
+adding the location information of each row as new columns, or new keys for
+JSON Lines.
+
+Usage
+
+	go install github.com/sams96/rgeo/cmd/rgeo@latest
+	rgeo -datasets Provinces10,Cities10 -lat latitude -lon longitude points.csv > out.csv
+
+It reads the files given, or stdin if there aren't any, and writes to stdout,
+or the file given with -o. The format is worked out from the file extension
+(.csv, .tsv or .tab, .jsonl or .ndjson), or can be set with -format, and
+defaults to CSV.
+
+CSV and TSV files need a header row, which is used to find the latitude and
+longitude columns named by -lat and -lon (lat and lon by default). The new
+columns are named after the JSON names of the rgeo.Location fields, e.g.
+country, country_code_3 and province, and -fields chooses which of them to
+add, e.g. -fields country,city. In JSON Lines the -lat and -lon keys can be
+numbers or strings, and only the fields that aren't empty are added.
+
+The datasets are chosen with -datasets, from Countries110, Countries10,
+Provinces10 and Cities10.
+
+Rows that can't be geocoded, because their coordinates are missing or invalid
+or they aren't in any of the datasets, are written to the file given with
+-reject along with an error column (or key) saying why. Without -reject they
+are kept in the output with the new columns left empty.
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sams96/rgeo"
+	"github.com/twpayne/go-geom"
+)
+
+func main() {
+	// Read args
+	datasetsFlag := flag.String("datasets", "Provinces10,Cities10", "Comma separated datasets to use")
+	formatFlag := flag.String("format", "", "Input format, csv, tsv or jsonl (default from the file extension, or csv)")
+	latFlag := flag.String("lat", "lat", "Name of the latitude column or key")
+	lonFlag := flag.String("lon", "lon", "Name of the longitude column or key")
+	fieldsFlag := flag.String("fields", "", "Comma separated location fields to add (default all)")
+	outFileName := flag.String("o", "", "Path to output file (default stdout)")
+	rejectFileName := flag.String("reject", "", "Path to write the rows that couldn't be geocoded to")
+
+	flag.Parse()
+
+	var sets []func() []byte
+
+	for _, name := range strings.Split(*datasetsFlag, ",") {
+		d, ok := rgeo.DatasetByName(name)
+		if !ok {
+			log.Fatalf("unknown dataset %q, expected Countries110, Countries10, Provinces10 or Cities10", name)
+		}
+
+		sets = append(sets, d)
+	}
+
+	fields, err := selectFields(*fieldsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	format := *formatFlag
+	if format == "" {
+		format = "csv"
+		if flag.NArg() > 0 {
+			format = formatFromName(flag.Arg(0))
+		}
+	}
+
+	if format != "csv" && format != "tsv" && format != "jsonl" {
+		log.Fatalf("unknown format %q", format)
+	}
+
+	r, err := rgeo.New(sets...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r.Build()
+
+	out := os.Stdout
+	if *outFileName != "" {
+		if out, err = os.Create(*outFileName); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	g := &geocoder{r: r, lat: *latFlag, lon: *lonFlag, fields: fields}
+	g.out = bufio.NewWriter(out)
+
+	var rej *os.File
+	if *rejectFileName != "" {
+		if rej, err = os.Create(*rejectFileName); err != nil {
+			log.Fatal(err)
+		}
+
+		g.rejected = bufio.NewWriter(rej)
+	}
+
+	inputs := flag.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+
+	for _, name := range inputs {
+		if err := g.processFile(name, format); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := g.out.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	if rej != nil {
+		if err := g.rejected.Flush(); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := rej.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if g.rejects > 0 {
+		log.Printf("%d of %d rows couldn't be geocoded", g.rejects, g.rows)
+	}
+}
+
+// field is a string field of rgeo.Location that can be added to the output.
+type field struct {
+	name  string
+	index int
+}
+
+// value returns the value of the field in l.
+func (f field) value(l rgeo.Location) string {
+	return reflect.ValueOf(l).Field(f.index).String()
+}
+
+// selectFields returns the fields with the given comma separated JSON names,
+// or all of them if names is empty.
+func selectFields(names string) ([]field, error) {
+	var all []field
+
+	t := reflect.TypeOf(rgeo.Location{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() != reflect.String {
+			continue
+		}
+
+		all = append(all, field{name: strings.Split(f.Tag.Get("json"), ",")[0], index: i})
+	}
+
+	if names == "" {
+		return all, nil
+	}
+
+	var ret []field
+
+	for _, name := range strings.Split(names, ",") {
+		i := slices.IndexFunc(all, func(f field) bool { return f.name == strings.TrimSpace(name) })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown field %q", name)
+		}
+
+		ret = append(ret, all[i])
+	}
+
+	return ret, nil
+}
+
+// formatFromName works out the format of a file from its extension.
+func formatFromName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".tsv", ".tab":
+		return "tsv"
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	default:
+		return "csv"
+	}
+}
+
+// geocoder adds the location information to each row.
+type geocoder struct {
+	r        *rgeo.Rgeo
+	lat, lon string
+	fields   []field
+
+	// Output, and the rows that couldn't be geocoded, which is nil without
+	// -reject
+	out, rejected *bufio.Writer
+
+	// Column names of the first CSV file, which the others have to match
+	header []string
+
+	rows, rejects int
+}
+
+// processFile geocodes the rows in the named file, or stdin if the name is
+// "-".
+func (g *geocoder) processFile(name, format string) error {
+	in := os.Stdin
+
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	var err error
+
+	switch format {
+	case "csv":
+		err = g.processCSV(in, ',')
+	case "tsv":
+		err = g.processCSV(in, '\t')
+	case "jsonl":
+		err = g.processJSONL(in)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}
+
+// geocode parses the coordinates and reverse geocodes them.
+func (g *geocoder) geocode(lat, lon string) (rgeo.Location, error) {
+	// ParseFloat accepts "NaN", which fails every comparison, so check that
+	// the coordinates are in range rather than out of it
+	y, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil || !(y >= -90 && y <= 90) {
+		return rgeo.Location{}, fmt.Errorf("bad latitude %q", lat)
+	}
+
+	x, err := strconv.ParseFloat(strings.TrimSpace(lon), 64)
+	if err != nil || !(x >= -180 && x <= 180) {
+		return rgeo.Location{}, fmt.Errorf("bad longitude %q", lon)
+	}
+
+	return g.r.ReverseGeocode(geom.Coord{x, y})
+}
+
+// processCSV geocodes the rows of a CSV file, or TSV with comma set to a tab.
+func (g *geocoder) processCSV(in io.Reader, comma rune) (err error) {
+	cr := csv.NewReader(in)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = comma == '\t'
+
+	out := csv.NewWriter(g.out)
+	out.Comma = comma
+
+	var rejected *csv.Writer
+	if g.rejected != nil {
+		rejected = csv.NewWriter(g.rejected)
+		rejected.Comma = comma
+	}
+
+	defer func() {
+		for _, w := range []*csv.Writer{out, rejected} {
+			if w != nil {
+				w.Flush()
+
+				if err == nil {
+					err = w.Error()
+				}
+			}
+		}
+	}()
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	latCol, lonCol := slices.Index(header, g.lat), slices.Index(header, g.lon)
+	if latCol < 0 || lonCol < 0 {
+		return fmt.Errorf("no %q and %q columns in header", g.lat, g.lon)
+	}
+
+	if g.header == nil {
+		g.header = header
+
+		row := slices.Clip(header)
+		for _, f := range g.fields {
+			row = append(row, f.name)
+		}
+
+		if err := out.Write(row); err != nil {
+			return err
+		}
+
+		if rejected != nil {
+			if err := rejected.Write(append(slices.Clip(header), "error")); err != nil {
+				return err
+			}
+		}
+	} else if !slices.Equal(header, g.header) {
+		return fmt.Errorf("header doesn't match the first file")
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		g.rows++
+
+		loc, err := rgeo.Location{}, fmt.Errorf("missing coordinates")
+		if latCol < len(rec) && lonCol < len(rec) {
+			loc, err = g.geocode(rec[latCol], rec[lonCol])
+		}
+
+		if err != nil {
+			g.rejects++
+
+			if rejected != nil {
+				if err := rejected.Write(append(rec, err.Error())); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		for _, f := range g.fields {
+			rec = append(rec, f.value(loc))
+		}
+
+		if err := out.Write(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// processJSONL geocodes the objects in a JSON Lines file. The new keys are
+// added to the end of each object, so the rest of it is left as it was.
+func (g *geocoder) processJSONL(in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(nil, 16<<20)
+
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		g.rows++
+
+		// Lines that aren't objects can't have the keys added, so rejected
+		// ones are kept as a string in a new object instead
+		object := json.Valid(line) && line[0] == '{'
+
+		loc, err := g.geocodeJSON(line)
+		if err != nil {
+			g.rejects++
+
+			if g.rejected != nil {
+				rej := line
+				keys, values := []string{"error"}, []string{err.Error()}
+
+				if !object {
+					rej = []byte("{}")
+					keys, values = []string{"input", "error"}, []string{string(line), err.Error()}
+				}
+
+				if _, err := g.rejected.Write(appendJSON(rej, keys, values)); err != nil {
+					return err
+				}
+
+				continue
+			}
+		}
+
+		var keys, values []string
+
+		for _, f := range g.fields {
+			if v := f.value(loc); v != "" {
+				keys = append(keys, f.name)
+				values = append(values, v)
+			}
+		}
+
+		if !object {
+			line = append(line, '\n')
+		} else {
+			line = appendJSON(line, keys, values)
+		}
+
+		if _, err := g.out.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return sc.Err()
+}
+
+// geocodeJSON reverse geocodes the coordinates in a JSON object.
+func (g *geocoder) geocodeJSON(line []byte) (rgeo.Location, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(line, &obj); err != nil {
+		return rgeo.Location{}, fmt.Errorf("not a JSON object")
+	}
+
+	lat, ok := obj[g.lat]
+	if !ok {
+		return rgeo.Location{}, fmt.Errorf("no %q key", g.lat)
+	}
+
+	lon, ok := obj[g.lon]
+	if !ok {
+		return rgeo.Location{}, fmt.Errorf("no %q key", g.lon)
+	}
+
+	return g.geocode(strings.Trim(string(lat), `"`), strings.Trim(string(lon), `"`))
+}
+
+// appendJSON adds the given keys and values to the end of a JSON object,
+// with a trailing newline.
+func appendJSON(obj []byte, keys, values []string) []byte {
+	var b bytes.Buffer
+
+	b.Write(obj[:len(obj)-1])
+	empty := len(bytes.TrimSpace(obj[1:len(obj)-1])) == 0
+
+	for i, k := range keys {
+		if i > 0 || !empty {
+			b.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(k)
+		value, _ := json.Marshal(values[i])
+
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+
+	b.WriteString("}\n")
+
+	return b.Bytes()
+}
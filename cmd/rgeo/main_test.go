@@ -0,0 +1,292 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sams96/rgeo"
+)
+
+const testgeo = `{
+	"type":"FeatureCollection",
+		"features":[
+			{"type":"Feature",
+			"properties":{"ADMIN":"Testland","ISO_A3":"TST"},
+			"geometry":{"type":"Polygon",
+				"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}
+		]
+	}`
+
+// newTestGeocoder returns a geocoder adding the country and country_code_3
+// fields, with its output and rejected rows going to the returned buffers.
+// The rejected rows are only kept if reject is true.
+func newTestGeocoder(t *testing.T, reject bool) (g *geocoder, out, rejected *bytes.Buffer) {
+	t.Helper()
+
+	r, err := rgeo.NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields, err := selectFields("country,country_code_3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, rejected = new(bytes.Buffer), new(bytes.Buffer)
+
+	g = &geocoder{r: r, lat: "lat", lon: "lon", fields: fields, out: bufio.NewWriter(out)}
+	if reject {
+		g.rejected = bufio.NewWriter(rejected)
+	}
+
+	return g, out, rejected
+}
+
+func TestProcessCSV(t *testing.T) {
+	var testdata = []struct {
+		name     string
+		in       string
+		comma    rune
+		reject   bool
+		expected string
+		rejected string
+		rejects  int
+		err      string
+	}{
+		{
+			name:     "csv",
+			in:       "id,lat,lon\n1,1,1\n2,10,10\n",
+			comma:    ',',
+			expected: "id,lat,lon,country,country_code_3\n1,1,1,Testland,TST\n2,10,10,,\n",
+			rejects:  1,
+		},
+		{
+			name:     "tsv",
+			in:       "lon\tlat\n1.5\t0.5\n",
+			comma:    '\t',
+			expected: "lon\tlat\tcountry\tcountry_code_3\n1.5\t0.5\tTestland\tTST\n",
+		},
+		{
+			name:   "reject",
+			in:     "id,lat,lon\n1,1,1\n2,NaN,NaN\n3,1,200\n4,1\n5,10,10\n",
+			comma:  ',',
+			reject: true,
+			expected: "id,lat,lon,country,country_code_3\n" +
+				"1,1,1,Testland,TST\n",
+			rejected: "id,lat,lon,error\n" +
+				"2,NaN,NaN,\"bad latitude \"\"NaN\"\"\"\n" +
+				"3,1,200,\"bad longitude \"\"200\"\"\"\n" +
+				"4,1,missing coordinates\n" +
+				"5,10,10,country not found\n",
+			rejects: 4,
+		},
+		{
+			name:     "empty",
+			comma:    ',',
+			expected: "",
+		},
+		{
+			name:  "no coordinates",
+			in:    "id,x,y\n1,1,1\n",
+			comma: ',',
+			err:   `no "lat" and "lon" columns in header`,
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			g, out, rejected := newTestGeocoder(t, test.reject)
+
+			err := g.processCSV(strings.NewReader(test.in), test.comma)
+			if (err == nil && test.err != "") || (err != nil && err.Error() != test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			g.out.Flush()
+
+			if diff := deep.Equal(test.expected, out.String()); diff != nil {
+				t.Error(diff)
+			}
+
+			if g.rejected != nil {
+				g.rejected.Flush()
+			}
+
+			if diff := deep.Equal(test.rejected, rejected.String()); diff != nil {
+				t.Error(diff)
+			}
+
+			if g.rejects != test.rejects {
+				t.Errorf("expected rejects: %d\n got: %d\n", test.rejects, g.rejects)
+			}
+		})
+	}
+}
+
+func TestProcessCSV_Header(t *testing.T) {
+	g, out, _ := newTestGeocoder(t, false)
+
+	// A second file only gets its rows added, if the header matches
+	if err := g.processCSV(strings.NewReader("lat,lon\n1,1\n"), ','); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.processCSV(strings.NewReader("lat,lon\n0.5,0.5\n"), ','); err != nil {
+		t.Fatal(err)
+	}
+
+	err := g.processCSV(strings.NewReader("lon,lat\n1,1\n"), ',')
+	if err == nil || err.Error() != "header doesn't match the first file" {
+		t.Errorf("expected error: header doesn't match the first file\n got: %s\n", err)
+	}
+
+	g.out.Flush()
+
+	expected := "lat,lon,country,country_code_3\n1,1,Testland,TST\n0.5,0.5,Testland,TST\n"
+	if diff := deep.Equal(expected, out.String()); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestProcessJSONL(t *testing.T) {
+	var testdata = []struct {
+		name     string
+		in       string
+		reject   bool
+		expected string
+		rejected string
+		rejects  int
+	}{
+		{
+			name: "objects",
+			in:   `{"id":1,"lat":1,"lon":1}` + "\n\n" + `{"lon":"0.5","lat":"0.5"}` + "\n",
+			expected: `{"id":1,"lat":1,"lon":1,"country":"Testland","country_code_3":"TST"}` + "\n" +
+				`{"lon":"0.5","lat":"0.5","country":"Testland","country_code_3":"TST"}` + "\n",
+		},
+		{
+			name: "kept without reject",
+			in:   `{"id":1,"lat":10,"lon":10}` + "\n" + `[1,1]` + "\n" + `not json` + "\n",
+			expected: `{"id":1,"lat":10,"lon":10}` + "\n" +
+				`[1,1]` + "\n" +
+				`not json` + "\n",
+			rejects: 3,
+		},
+		{
+			name: "reject",
+			in: `{"id":1,"lat":1,"lon":1}` + "\n" +
+				`{"id":2,"lat":NaN,"lon":NaN}` + "\n" +
+				`{"id":3,"lat":"NaN","lon":"NaN"}` + "\n" +
+				`{"id":4,"lat":1}` + "\n" +
+				`{}` + "\n" +
+				`[1,1]` + "\n",
+			reject:   true,
+			expected: `{"id":1,"lat":1,"lon":1,"country":"Testland","country_code_3":"TST"}` + "\n",
+			rejected: `{"input":"{\"id\":2,\"lat\":NaN,\"lon\":NaN}","error":"not a JSON object"}` + "\n" +
+				`{"id":3,"lat":"NaN","lon":"NaN","error":"bad latitude \"NaN\""}` + "\n" +
+				`{"id":4,"lat":1,"error":"no \"lon\" key"}` + "\n" +
+				`{"error":"no \"lat\" key"}` + "\n" +
+				`{"input":"[1,1]","error":"not a JSON object"}` + "\n",
+			rejects: 5,
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			g, out, rejected := newTestGeocoder(t, test.reject)
+
+			if err := g.processJSONL(strings.NewReader(test.in)); err != nil {
+				t.Fatal(err)
+			}
+
+			g.out.Flush()
+
+			if diff := deep.Equal(test.expected, out.String()); diff != nil {
+				t.Error(diff)
+			}
+
+			if g.rejected != nil {
+				g.rejected.Flush()
+			}
+
+			if diff := deep.Equal(test.rejected, rejected.String()); diff != nil {
+				t.Error(diff)
+			}
+
+			if g.rejects != test.rejects {
+				t.Errorf("expected rejects: %d\n got: %d\n", test.rejects, g.rejects)
+			}
+		})
+	}
+}
+
+func TestAppendJSON(t *testing.T) {
+	var testdata = []struct {
+		name     string
+		in       string
+		keys     []string
+		values   []string
+		expected string
+	}{
+		{
+			name:     "object",
+			in:       `{"a":1}`,
+			keys:     []string{"b", "c"},
+			values:   []string{"x", `"y"`},
+			expected: `{"a":1,"b":"x","c":"\"y\""}` + "\n",
+		},
+		{
+			name:     "empty object",
+			in:       `{}`,
+			keys:     []string{"b"},
+			values:   []string{"x"},
+			expected: `{"b":"x"}` + "\n",
+		},
+		{
+			name:     "empty object with spaces",
+			in:       `{ }`,
+			keys:     []string{"b"},
+			values:   []string{"x"},
+			expected: `{ "b":"x"}` + "\n",
+		},
+		{
+			name:     "no keys",
+			in:       `{"a":1}`,
+			expected: `{"a":1}` + "\n",
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res := appendJSON([]byte(test.in), test.keys, test.values)
+			if diff := deep.Equal(test.expected, string(res)); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// ErrCodeNotFound is returned by ByCountryCode and ByProvinceCode when none
+// of the features have the given code.
+var ErrCodeNotFound = errors.New("code not found")
+
+// Feature is a country or province found by its code, as returned by
+// ByCountryCode and ByProvinceCode.
+type Feature struct {
+	Location Location `json:"location"`
+
+	// Polygon of the feature, a *geom.Polygon or *geom.MultiPolygon wound as
+	// RFC 7946 says
+	Geometry geom.T `json:"-"`
+
+	// Centroid of the polygon on the sphere, as longitude then latitude
+	Centroid geom.Coord `json:"centroid"`
+
+	// Bounding box of the polygon. Where it crosses the antimeridian the
+	// minimum longitude is greater than the maximum, as with RectFromBounds.
+	Bounds *geom.Bounds `json:"-"`
+}
+
+// codeIndex holds the shapes with each code, see ByCountryCode.
+type codeIndex struct {
+	// By CountryCode2 and CountryCode3, which can't clash as they're
+	// different lengths
+	countries map[string][]s2.Shape
+
+	// By ProvinceCode
+	provinces map[string][]s2.Shape
+}
+
+// ByCountryCode returns the country with the given ISO 3166-1 alpha-2 or
+// alpha-3 code, e.g. "FR" or "FRA", in any case. Only the country fields of
+// the Location are filled in.
+//
+// The feature is made up of the features from the datasets given to New with
+// that code that are the least detailed, so a country is taken from Countries10
+// if it's loaded, or put together from its provinces in Provinces10 otherwise.
+func (r *Rgeo) ByCountryCode(code string) (Feature, error) {
+	r.buildCodes()
+
+	f, err := r.featureFromShapes(r.codes.countries[normaliseCode(code)])
+	if err != nil {
+		return Feature{}, err
+	}
+
+	f.Location = Location{
+		Country:      f.Location.Country,
+		CountryLong:  f.Location.CountryLong,
+		CountryCode2: f.Location.CountryCode2,
+		CountryCode3: f.Location.CountryCode3,
+		Continent:    f.Location.Continent,
+		Region:       f.Location.Region,
+		SubRegion:    f.Location.SubRegion,
+		names:        f.Location.names,
+		poly:         f.Location.poly,
+	}
+
+	return f, nil
+}
+
+// ByProvinceCode returns the province with the given ISO 3166-2 code, e.g.
+// "US-CA", in any case. This needs a dataset with provinces, such as
+// Provinces10.
+func (r *Rgeo) ByProvinceCode(code string) (Feature, error) {
+	r.buildCodes()
+
+	return r.featureFromShapes(r.codes.provinces[normaliseCode(code)])
+}
+
+// featureFromShapes puts together a Feature from the least detailed of the
+// shapes.
+func (r *Rgeo) featureFromShapes(shapes []s2.Shape) (Feature, error) {
+	if len(shapes) == 0 {
+		return Feature{}, ErrCodeNotFound
+	}
+
+	least := detail(r.locs[shapes[0]].Location)
+	for _, shape := range shapes[1:] {
+		least = min(least, detail(r.locs[shape].Location))
+	}
+
+	var (
+		f        Feature
+		polygons []*geom.Polygon
+		centroid s2.Point
+		bound    s2.Rect
+		n        int
+	)
+
+	for _, shape := range shapes {
+		loc := r.locs[shape].Location
+		if detail(loc) != least {
+			continue
+		}
+
+		p := shape.(*s2.Polygon)
+
+		if n == 0 {
+			f.Location = loc
+			bound = p.RectBound()
+		} else {
+			// The properties and polygon of one part would be misleading
+			f.Location.props, f.Location.poly = nil, nil
+			bound = bound.Union(p.RectBound())
+		}
+
+		n++
+
+		// Polygon centroids are multiplied by their area, so the sum gives
+		// the centroid of all of them
+		centroid = s2.Point{Vector: centroid.Add(p.Centroid().Vector)}
+
+		switch g := geometryFromPolygon(p, 0).(type) {
+		case *geom.Polygon:
+			polygons = append(polygons, g)
+		case *geom.MultiPolygon:
+			for i := 0; i < g.NumPolygons(); i++ {
+				polygons = append(polygons, g.Polygon(i))
+			}
+		}
+	}
+
+	if len(polygons) == 1 {
+		f.Geometry = polygons[0]
+	} else {
+		mp := geom.NewMultiPolygon(geom.XY)
+		for _, p := range polygons {
+			if err := mp.Push(p); err != nil {
+				return Feature{}, err
+			}
+		}
+
+		f.Geometry = mp
+	}
+
+	f.Centroid = coordFromPoint(s2.Point{Vector: centroid.Normalize()})
+	lo, hi := bound.Lo(), bound.Hi()
+	f.Bounds = geom.NewBounds(geom.XY).Set(
+		lo.Lng.Degrees(), lo.Lat.Degrees(), hi.Lng.Degrees(), hi.Lat.Degrees(),
+	)
+
+	return f, nil
+}
+
+// buildCodes builds the index of codes, the first time it's called.
+func (r *Rgeo) buildCodes() {
+	r.codesOnce.Do(func() {
+		r.codes = codeIndex{
+			countries: make(map[string][]s2.Shape),
+			provinces: make(map[string][]s2.Shape),
+		}
+
+		// Go through the shapes in the order they were added, so that the
+		// features are put together the same way each time
+		for id := 0; id < r.index.Len(); id++ {
+			shape := r.index.Shape(int32(id))
+			if _, ok := shape.(*s2.Polygon); !ok {
+				continue
+			}
+
+			loc := r.locs[shape].Location
+
+			for _, code := range []string{loc.CountryCode2, loc.CountryCode3} {
+				if code = normaliseCode(code); code != "" {
+					r.codes.countries[code] = append(r.codes.countries[code], shape)
+				}
+			}
+
+			if code := normaliseCode(loc.ProvinceCode); code != "" {
+				r.codes.provinces[code] = append(r.codes.provinces[code], shape)
+			}
+		}
+	})
+}
+
+// normaliseCode puts a code in upper case, or returns nothing if it's Natural
+// Earth's placeholder for an unknown code.
+func normaliseCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "-99" {
+		return ""
+	}
+
+	return code
+}
+
+// detail returns how detailed a Location is, from 0 for a country to 3 for a
+// city.
+func detail(l Location) int {
+	switch {
+	case l.City != "":
+		return 3
+	case l.District != "":
+		return 2
+	case l.Province != "" || l.ProvinceCode != "":
+		return 1
+	}
+
+	return 0
+}
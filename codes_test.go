@@ -0,0 +1,240 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/twpayne/go-geom"
+)
+
+func TestByCode(t *testing.T) {
+	testprovinces := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ADMIN":"Testland","ISO_A2":"TS","ISO_A3":"TST",
+					"name":"Westshire","iso_3166_2":"TS-01"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"ADMIN":"Testland","ISO_A2":"TS","ISO_A3":"TST",
+					"name":"Eastshire","iso_3166_2":"TS-02"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[2,0],[4,0],[4,2],[2,2],[2,0]]]}},
+				{"type":"Feature",
+				"properties":{"ADMIN":"Unknownland","ISO_A2":"-99","ISO_A3":"-99"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[10,10],[11,10],[11,11],[10,11],[10,10]]]}}
+			]
+		}`
+
+	testcountries := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ADMIN":"Testland","ISO_A2":"TS","ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[4,0],[4,2],[0,2],[0,0]]]}}
+			]
+		}`
+
+	type result struct {
+		Location Location
+		Type     string
+		Centroid geom.Coord
+		Bounds   []float64
+	}
+
+	var testdata = []struct {
+		name      string
+		countries bool
+		province  bool
+		code      string
+		expected  result
+		err       error
+	}{
+		{
+			name: "country from provinces",
+			code: "TST",
+			expected: result{
+				Location: Location{Country: "Testland", CountryCode2: "TS", CountryCode3: "TST"},
+				Type:     "MultiPolygon",
+				Centroid: geom.Coord{2, 1},
+				Bounds:   []float64{0, 0, 4, 2},
+			},
+		},
+		{
+			name:      "country",
+			countries: true,
+			code:      "ts",
+			expected: result{
+				Location: Location{Country: "Testland", CountryCode2: "TS", CountryCode3: "TST"},
+				Type:     "Polygon",
+				Centroid: geom.Coord{2, 1},
+				Bounds:   []float64{0, 0, 4, 2},
+			},
+		},
+		{
+			name:     "province",
+			province: true,
+			code:     "TS-02",
+			expected: result{
+				Location: Location{
+					Country:      "Testland",
+					CountryCode2: "TS",
+					CountryCode3: "TST",
+					Province:     "Eastshire",
+					ProvinceCode: "TS-02",
+				},
+				Type:     "Polygon",
+				Centroid: geom.Coord{3, 1},
+				Bounds:   []float64{2, 0, 4, 2},
+			},
+		},
+		{
+			name: "unknown code",
+			code: "-99",
+			err:  ErrCodeNotFound,
+		},
+		{
+			name:     "missing province",
+			province: true,
+			code:     "TS-03",
+			err:      ErrCodeNotFound,
+		},
+	}
+
+	provinces, err := New(func() []byte { return compressData(t, testprovinces) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	both, err := New(
+		func() []byte { return compressData(t, testcountries) },
+		func() []byte { return compressData(t, testprovinces) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			r := provinces
+			if test.countries {
+				r = both
+			}
+
+			lookup := r.ByCountryCode
+			if test.province {
+				lookup = r.ByProvinceCode
+			}
+
+			f, err := lookup(test.code)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+
+			if err != nil {
+				return
+			}
+
+			res := result{
+				Location: f.Location,
+				Centroid: geom.Coord{f.Centroid.X(), f.Centroid.Y()},
+				Bounds:   []float64{f.Bounds.Min(0), f.Bounds.Min(1), f.Bounds.Max(0), f.Bounds.Max(1)},
+			}
+
+			// The centroid is on the sphere so only roughly in the middle, and
+			// the bounds are padded a little
+			for _, v := range [][2][]float64{
+				{res.Centroid, test.expected.Centroid},
+				{res.Bounds, test.expected.Bounds},
+			} {
+				for i := range v[0] {
+					if math.Abs(v[0][i]-v[1][i]) < 0.01 {
+						v[0][i] = v[1][i]
+					}
+				}
+			}
+
+			switch f.Geometry.(type) {
+			case *geom.Polygon:
+				res.Type = "Polygon"
+			case *geom.MultiPolygon:
+				res.Type = "MultiPolygon"
+			}
+
+			if diff := deep.Equal(test.expected, res); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestByCountryCode_Countries(t *testing.T) {
+	r, err := New(Countries110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gb, err := r.ByCountryCode("GBR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gb.Location.Country != "United Kingdom" {
+		t.Errorf("expected: United Kingdom\n got: %s\n", gb.Location.Country)
+	}
+
+	loc, err := r.ReverseGeocode(gb.Centroid)
+	if err != nil || loc.CountryCode3 != "GBR" {
+		t.Errorf("expected centroid in GBR, got: %s, %v", loc.CountryCode3, err)
+	}
+
+	// Fiji crosses the antimeridian, so the bounds wrap around
+	fj, err := r.ByCountryCode("FJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fj.Bounds.Min(0) < fj.Bounds.Max(0) {
+		t.Errorf("expected bounds across the antimeridian, got: %v", fj.Bounds)
+	}
+
+	// France and Norway's ISO_A2 and ISO_A3 are -99 in Natural Earth, so the
+	// codes come from ISO_A2_EH and ISO_A3_EH instead
+	for code, country := range map[string]string{
+		"FRA": "France",
+		"FR":  "France",
+		"NOR": "Norway",
+		"NO":  "Norway",
+	} {
+		f, err := r.ByCountryCode(code)
+		if err != nil {
+			t.Errorf("%s: %s", code, err)
+			continue
+		}
+
+		if f.Location.Country != country {
+			t.Errorf("%s: expected: %s\n got: %s\n", code, country, f.Location.Country)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// crossingTolerance is how close together two boundary crossings on a line
+// can be before they're treated as the same one, e.g. where neighbouring
+// polygons share a border. It's roughly 6mm on the Earth's surface.
+const crossingTolerance = s1.Angle(1e-9)
+
+// Segment is a part of a line within a single jurisdiction, as returned by
+// Crossings.
+type Segment struct {
+	// Location of this part of the line, which is empty where it isn't in any
+	// of the polygons
+	Location Location `json:"location"`
+
+	// Coordinates where the line enters and leaves this Location
+	Entry geom.Coord `json:"entry"`
+	Exit  geom.Coord `json:"exit"`
+
+	// Great circle distance travelled along the line in this Location, see
+	// ReverseGeocodeNearest for converting it to a distance on the Earth's
+	// surface
+	Distance s1.Angle `json:"distance"`
+}
+
+// Crossings splits the given line, such as a route, where it crosses the
+// borders of the polygons, and returns the parts in order along with the
+// Location of each. The edges of the line are great circles, as they are for
+// the polygons, so long edges can cross borders that a straight line on a map
+// wouldn't.
+//
+// A new Segment is started whenever the line enters or leaves any polygon, so
+// with Provinces10 there's one for each province, and parts of the line
+// outside of all of them (e.g. at sea) are included with an empty Location.
+// ErrLocationNotFound is returned if none of the line is inside any polygon.
+func (r *Rgeo) Crossings(line *geom.LineString) ([]Segment, error) {
+	n := line.NumCoords()
+	if n < 2 {
+		return nil, fmt.Errorf("line needs at least 2 points, got %d", n)
+	}
+
+	edges := s2.NewCrossingEdgeQuery(r.index)
+	query := s2.NewContainsPointQuery(r.index, s2.VertexModelOpen)
+
+	var (
+		segs   []Segment
+		shapes []s2.Shape // Shapes containing the last segment
+		found  bool
+	)
+
+	for i := 0; i < n-1; i++ {
+		from := geom.Coord{line.Coord(i).X(), line.Coord(i).Y()}
+		to := geom.Coord{line.Coord(i + 1).X(), line.Coord(i + 1).Y()}
+
+		a, b := pointFromCoord(from), pointFromCoord(to)
+		if a == b {
+			continue
+		}
+
+		length := a.Distance(b)
+		start, entry := s1.Angle(0), from
+
+		for _, end := range append(edgeCrossings(edges, a, b), length) {
+			exit := to
+			if end < length {
+				exit = coordFromPoint(s2.InterpolateAtDistance(end, a, b))
+			}
+
+			// Between two crossings the same shapes contain the whole line
+			inside := query.ContainingShapes(s2.InterpolateAtDistance((start+end)/2, a, b))
+
+			if len(segs) > 0 && slices.Equal(shapes, inside) {
+				segs[len(segs)-1].Exit = exit
+				segs[len(segs)-1].Distance += end - start
+			} else {
+				var loc Location
+				if len(inside) > 0 {
+					loc = r.combineLocations(inside)
+					found = true
+				}
+
+				segs = append(segs, Segment{
+					Location: loc,
+					Entry:    entry,
+					Exit:     exit,
+					Distance: end - start,
+				})
+				shapes = inside
+			}
+
+			start, entry = end, exit
+		}
+	}
+
+	if !found {
+		return nil, ErrLocationNotFound
+	}
+
+	return segs, nil
+}
+
+// edgeCrossings returns where the edge from a to b crosses the polygon
+// boundaries, as distances from a in increasing order, leaving out any at
+// either end of the edge.
+func edgeCrossings(query *s2.CrossingEdgeQuery, a, b s2.Point) []s1.Angle {
+	var dists []s1.Angle
+
+	for shape, ids := range query.CrossingsEdgeMap(a, b, s2.CrossingTypeAll) {
+		for _, id := range ids {
+			e := shape.Edge(id)
+			dists = append(dists, a.Distance(s2.Intersection(a, b, e.V0, e.V1)))
+		}
+	}
+
+	sort.Slice(dists, func(i, j int) bool { return dists[i] < dists[j] })
+
+	length := a.Distance(b)
+	ret := dists[:0]
+	last := s1.Angle(0)
+
+	for _, d := range dists {
+		if d-last > crossingTolerance && length-d > crossingTolerance {
+			ret = append(ret, d)
+			last = d
+		}
+	}
+
+	return ret
+}
+
+// coordFromPoint converts an s2.Point back to a longitude/latitude
+// coordinate.
+func coordFromPoint(p s2.Point) geom.Coord {
+	ll := s2.LatLngFromPoint(p)
+	return geom.Coord{ll.Lng.Degrees(), ll.Lat.Degrees()}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/golang/geo/s1"
+	"github.com/twpayne/go-geom"
+)
+
+func TestCrossings(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,-1],[2,-1],[2,1],[0,1],[0,-1]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TSU"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[2,-1],[4,-1],[4,1],[2,1],[2,-1]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected []Segment
+		err      string
+	}{
+		{
+			name: "across both",
+			in:   []float64{-1, 0, 5, 0},
+			expected: []Segment{
+				{Entry: []float64{-1, 0}, Exit: []float64{0, 0}, Distance: s1.Degree},
+				{
+					Location: Location{CountryCode3: "TST"},
+					Entry:    []float64{0, 0},
+					Exit:     []float64{2, 0},
+					Distance: 2 * s1.Degree,
+				},
+				{
+					Location: Location{CountryCode3: "TSU"},
+					Entry:    []float64{2, 0},
+					Exit:     []float64{4, 0},
+					Distance: 2 * s1.Degree,
+				},
+				{Entry: []float64{4, 0}, Exit: []float64{5, 0}, Distance: s1.Degree},
+			},
+		},
+		{
+			name: "several edges in one",
+			in:   []float64{0.5, 0, 1, 0.5, 1.5, 0},
+			expected: []Segment{
+				{
+					Location: Location{CountryCode3: "TST"},
+					Entry:    []float64{0.5, 0},
+					Exit:     []float64{1.5, 0},
+					Distance: 2 * (s1.Angle(math.Sqrt(0.5)) * s1.Degree),
+				},
+			},
+		},
+		{
+			name: "back and forth",
+			in:   []float64{1, 0, 3, 0, 1, 0.5},
+			expected: []Segment{
+				{
+					Location: Location{CountryCode3: "TST"},
+					Entry:    []float64{1, 0},
+					Exit:     []float64{2, 0},
+					Distance: s1.Degree,
+				},
+				{
+					Location: Location{CountryCode3: "TSU"},
+					Entry:    []float64{2, 0},
+					Exit:     []float64{2, 0.25},
+					Distance: s1.Angle(1+math.Sqrt(1+0.0625)) * s1.Degree,
+				},
+				{
+					Location: Location{CountryCode3: "TST"},
+					Entry:    []float64{2, 0.25},
+					Exit:     []float64{1, 0.5},
+					Distance: s1.Angle(math.Sqrt(1+0.0625)) * s1.Degree,
+				},
+			},
+		},
+		{
+			name: "outside",
+			in:   []float64{5, 5, 6, 6},
+			err:  ErrLocationNotFound.Error(),
+		},
+		{
+			name: "single point",
+			in:   []float64{1, 0},
+			err:  "line needs at least 2 points, got 1",
+		},
+	}
+
+	r, err := NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			segs, err := r.Crossings(geom.NewLineStringFlat(geom.XY, test.in))
+			if (err == nil && test.err != "") || (err != nil && err.Error() != test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+
+			// The crossings are on great circles and the test values aren't,
+			// so only compare them roughly
+			for i := range segs {
+				for _, c := range []geom.Coord{segs[i].Entry, segs[i].Exit} {
+					c[0] = math.Round(c[0]*100) / 100
+					c[1] = math.Round(c[1]*100) / 100
+				}
+
+				if i < len(test.expected) && math.Abs(float64(segs[i].Distance-test.expected[i].Distance)) < 1e-4 {
+					segs[i].Distance = test.expected[i].Distance
+				}
+			}
+
+			if diff := deep.Equal(test.expected, segs); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestCrossings_Countries(t *testing.T) {
+	r, err := New(Countries110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// London to Paris to Berlin
+	segs, err := r.Crossings(geom.NewLineStringFlat(geom.XY, []float64{
+		-0.12, 51.5, 2.35, 48.86, 13.4, 52.52,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var countries []string
+	for _, s := range segs {
+		countries = append(countries, s.Location.Country)
+	}
+
+	expected := []string{"United Kingdom", "", "France", "Belgium", "Germany"}
+	if diff := deep.Equal(expected, countries); diff != nil {
+		t.Error(diff)
+	}
+}
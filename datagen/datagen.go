@@ -27,6 +27,22 @@ Usage
 
 The variable containing the data will be named outfile.
 
+Second level administrative divisions (counties, districts etc.) can be read
+from GADM or geoBoundaries level 2 GeoJSON with -admin2 gadm or -admin2
+geoboundaries, and attached to the provinces and countries containing them with
+-within, e.g.
+
+	go run datagen.go -o Districts -admin2 geoboundaries -within provinces.geojson geoBoundaries-USA-ADM2.geojson
+
+where provinces.geojson is Provinces10 or another GeoJSON file with the
+province and country properties. With -within each feature gets the properties
+of the province containing most of its points, and the rest come from the
+admin-2 file where it has them.
+
+With the -snapshot flag datagen also writes outfile.rgeo, a prebuilt snapshot
+of the polygons which can be loaded with rgeo.Load without parsing the GeoJSON.
+The shape index still has to be built after loading it.
+
 rgeo reads the location information from the following GeoJSON properties:
 
 	- Country:      "ADMIN" or "admin"
@@ -38,7 +54,23 @@ rgeo reads the location information from the following GeoJSON properties:
 	- SubRegion:    "SUBREGION"
 	- Province:     "name"
 	- ProvinceCode: "iso_3166_2"
+	- District:     "district"
 	- City:         "name_conve"
+	- TimeZone:     "tzid"
+	- WaterBody:    "water_body"
+	- MaritimeCountryCode3: "ISO_SOV1"
+	- MaritimeZoneType:     "POL_TYPE"
+
+All of the properties are kept in the output, including the names in other
+languages that rgeo.WithLanguages uses: "NAME_DE", "NAME_FR" etc. for
+countries, and "name_de", "name_fr" etc. for provinces and cities, and the
+point of view properties such as "ADM0_A3_IN" and "FCLASS_IN", the first of
+which rgeo.WithWorldview uses.
+
+If your GeoJSON uses different properties you can tell rgeo which ones to use
+with rgeo.WithPropertyMapping, or rename them with the -rename flag, e.g.
+-rename name=water_body for the Natural Earth marine polygons, whose names would
+otherwise be read as provinces.
 */
 package main
 
@@ -53,6 +85,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/sams96/rgeo"
+	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-geom/encoding/geojson"
 )
 
@@ -61,6 +95,10 @@ func main() {
 	outFileName := flag.String("o", "", "Path to output file")
 	neCommentFlag := flag.Bool("ne", false, "Use Natural earth comment")
 	mergeFileName := flag.String("merge", "", "File to get extra info from")
+	snapshotFlag := flag.Bool("snapshot", false, "Also write a prebuilt snapshot for rgeo.Load")
+	renameFlag := flag.String("rename", "", "Comma separated old=new pairs of properties to rename")
+	admin2Flag := flag.String("admin2", "", "Read districts from GADM (gadm) or geoBoundaries (geoboundaries) level 2 GeoJSON")
+	withinFileName := flag.String("within", "", "File of provinces to attach each feature to the one containing it")
 
 	flag.Parse()
 
@@ -74,6 +112,22 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if err := renameProperties(feats, *renameFlag); err != nil {
+		log.Fatal(err)
+	}
+
+	if *withinFileName != "" {
+		if err := mergeWithin(feats, *withinFileName); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if *admin2Flag != "" {
+		if err := readAdmin2(feats, *admin2Flag); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	var pre string
 	if *neCommentFlag {
 		pre = "https://github.com/nvkelso/natural-earth-vector/blob/master/geojson/"
@@ -83,6 +137,9 @@ func main() {
 	if *mergeFileName != "" {
 		files = append(files, *mergeFileName)
 	}
+	if *withinFileName != "" {
+		files = append(files, *withinFileName)
+	}
 
 	resp, err := json.Marshal(feats)
 	if err != nil {
@@ -107,6 +164,12 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if *snapshotFlag {
+		if err := writeSnapshot(fmt.Sprintf("%s.rgeo", *outFileName), resp); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	fReadme, _ := os.Create(fmt.Sprintf("%s.txt", *outFileName))
 	fmt.Fprintf(fReadme, "%s %s", strings.TrimSuffix(*outFileName, ".go"), "uses data from "+printSlice(prefixSlice(pre, files)))
 }
@@ -175,6 +238,214 @@ func readInput(f string, mergeData *geojson.FeatureCollection) (*geojson.Feature
 	return &fc, nil
 }
 
+// renameProperties renames the properties of every feature according to a list
+// of old=new pairs, e.g. "name=water_body,name_fr=water_body_fr"
+func renameProperties(fc *geojson.FeatureCollection, pairs string) error {
+	if pairs == "" {
+		return nil
+	}
+
+	renames := make(map[string]string)
+
+	for _, pair := range strings.Split(pairs, ",") {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok || from == "" || to == "" {
+			return fmt.Errorf("bad rename %q, should be old=new", pair)
+		}
+
+		renames[from] = to
+	}
+
+	for _, feat := range fc.Features {
+		props := make(map[string]interface{}, len(feat.Properties))
+
+		for k, v := range feat.Properties {
+			if to, ok := renames[k]; ok {
+				k = to
+			}
+
+			props[k] = v
+		}
+
+		feat.Properties = props
+	}
+
+	return nil
+}
+
+// admin2Keys lists, for each source of admin-2 data, the properties to copy to
+// the ones rgeo reads, if they aren't already set (e.g. by -within).
+var admin2Keys = map[string][][2]string{
+	"gadm": {
+		{"NAME_2", "district"},
+		{"NAME_1", "name"},
+		{"COUNTRY", "admin"},
+		{"GID_0", "ISO_A3"},
+	},
+	"geoboundaries": {
+		{"shapeName", "district"},
+		{"shapeGroup", "ISO_A3"},
+	},
+}
+
+// readAdmin2 copies the names of the districts, and of their provinces and
+// countries where the source has them, to the properties that rgeo reads
+func readAdmin2(fc *geojson.FeatureCollection, source string) error {
+	keys, ok := admin2Keys[strings.ToLower(source)]
+	if !ok {
+		return fmt.Errorf("unknown admin-2 source %q, should be gadm or geoboundaries", source)
+	}
+
+	for _, feat := range fc.Features {
+		for _, k := range keys {
+			v, ok := feat.Properties[k[0]]
+			if _, set := feat.Properties[k[1]]; ok && !set {
+				feat.Properties[k[1]] = v
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeWithin copies the properties of the province that each feature is in
+// to the feature, without overwriting its own. Each feature is matched to the
+// province containing most of a sample of its points, as the borders from
+// different sources never quite line up
+func mergeWithin(fc *geojson.FeatureCollection, fileName string) error {
+	r, err := rgeo.NewWithOptions(rgeo.WithFiles(fileName), rgeo.WithProperties())
+	if err != nil {
+		return err
+	}
+
+	r.Build()
+
+	for i, feat := range fc.Features {
+		votes := make(map[int]int)
+		props := make(map[int]map[string]interface{})
+
+		for _, pt := range samplePoints(feat.Geometry, 5) {
+			matches, err := r.ReverseGeocodeAll(pt)
+			if err != nil {
+				continue
+			}
+
+			votes[matches[0].Feature]++
+			props[matches[0].Feature] = matches[0].Location.Properties()
+		}
+
+		best := -1
+		for j, n := range votes {
+			if best < 0 || n > votes[best] || (n == votes[best] && j < best) {
+				best = j
+			}
+		}
+
+		if best < 0 {
+			log.Printf("feature %d isn't within any of %s", i, fileName)
+			continue
+		}
+
+		for k, v := range props[best] {
+			if _, ok := feat.Properties[k]; !ok {
+				feat.Properties[k] = v
+			}
+		}
+	}
+
+	return nil
+}
+
+// samplePoints returns the points of an n by n grid over the largest polygon
+// in g that are inside its outer ring, or the coordinates of the ring itself if
+// it's too thin for any of the grid to be inside
+func samplePoints(g geom.T, n int) []geom.Coord {
+	var ring *geom.LinearRing
+
+	switch t := g.(type) {
+	case *geom.Polygon:
+		ring = t.LinearRing(0)
+	case *geom.MultiPolygon:
+		for i := 0; i < t.NumPolygons(); i++ {
+			if r := t.Polygon(i).LinearRing(0); ring == nil || r.NumCoords() > ring.NumCoords() {
+				ring = r
+			}
+		}
+	}
+
+	if ring == nil || ring.NumCoords() == 0 {
+		return nil
+	}
+
+	var pts []geom.Coord
+
+	b := ring.Bounds()
+	dx := (b.Max(0) - b.Min(0)) / float64(n)
+	dy := (b.Max(1) - b.Min(1)) / float64(n)
+
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			pt := geom.Coord{b.Min(0) + (float64(i)+0.5)*dx, b.Min(1) + (float64(j)+0.5)*dy}
+			if inRing(ring, pt) {
+				pts = append(pts, pt)
+			}
+		}
+	}
+
+	if len(pts) == 0 {
+		for i := 0; i < ring.NumCoords(); i++ {
+			pts = append(pts, ring.Coord(i))
+		}
+	}
+
+	return pts
+}
+
+// inRing reports whether pt is inside the ring, treating the coordinates as
+// planar, which is close enough for picking sample points
+func inRing(ring *geom.LinearRing, pt geom.Coord) bool {
+	in := false
+	n := ring.NumCoords()
+
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := ring.Coord(i), ring.Coord(j)
+		if (a.Y() > pt.Y()) != (b.Y() > pt.Y()) &&
+			pt.X() < (b.X()-a.X())*(pt.Y()-a.Y())/(b.Y()-a.Y())+a.X() {
+			in = !in
+		}
+	}
+
+	return in
+}
+
+// writeSnapshot converts the GeoJSON into a gzip compressed rgeo snapshot, so
+// that it can be loaded with rgeo.Load without any parsing
+func writeSnapshot(fileName string, geoJSON []byte) error {
+	r, err := rgeo.NewFromReaders(bytes.NewReader(geoJSON))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	zw, _ := gzip.NewWriterLevel(f, 9)
+
+	if err := r.Save(zw); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
 // printSlice prints a slice of strings with commas and an ampersand if needed
 func printSlice(in []string) string {
 	n := len(in)
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestReverseGeocode_District(t *testing.T) {
+	// Laid out like the output of datagen -admin2 with -within, so the
+	// districts carry the properties of their province and country
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"district":"Test County","shapeName":"Test County",
+					"name":"Test Province","iso_3166_2":"TS-TP","admin":"Testland","ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"district":"Other County",
+					"name":"Test Province","iso_3166_2":"TS-TP","admin":"Testland","ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1,0],[2,0],[2,1],[1,1],[1,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected Location
+		str      string
+	}{
+		{
+			name: "Test County",
+			in:   []float64{0.5, 0.5},
+			expected: Location{
+				Country:      "Testland",
+				CountryCode3: "TST",
+				Province:     "Test Province",
+				ProvinceCode: "TS-TP",
+				District:     "Test County",
+			},
+			str: "<Location> Test County, Test Province, Testland (TST),",
+		},
+		{
+			name: "Other County",
+			in:   []float64{1.5, 0.5},
+			expected: Location{
+				Country:      "Testland",
+				CountryCode3: "TST",
+				Province:     "Test Province",
+				ProvinceCode: "TS-TP",
+				District:     "Other County",
+			},
+			str: "<Location> Other County, Test Province, Testland (TST),",
+		},
+	}
+
+	r, err := NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			loc, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(test.expected, loc); diff != nil {
+				t.Error(diff)
+			}
+			if loc.String() != test.str {
+				t.Errorf("expected: %s\n got: %s\n", test.str, loc)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package rgeo
 
-// embedding files individually here to allow the linker to strip out unused ones
-import _ "embed"
+import (
+	// embedding files individually here to allow the linker to strip out unused ones
+	_ "embed"
+	"strings"
+)
 
 //go:embed data/Cities10.gz
 var cities10 []byte
@@ -30,3 +33,23 @@ var provinces10 []byte
 func Provinces10() []byte {
 	return provinces10
 }
+
+// DatasetByName returns the included dataset with the given name, e.g.
+// "Provinces10", ignoring case, for choosing datasets from flags or config.
+// Using it links every dataset into the binary.
+func DatasetByName(name string) (func() []byte, bool) {
+	// A switch rather than a map, so that the datasets are only linked in
+	// when this is used
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "cities10":
+		return Cities10, true
+	case "countries10":
+		return Countries10, true
+	case "countries110":
+		return Countries110, true
+	case "provinces10":
+		return Provinces10, true
+	}
+
+	return nil, false
+}
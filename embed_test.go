@@ -0,0 +1,48 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDatasetByName(t *testing.T) {
+	var testdata = []struct {
+		name     string
+		in       string
+		expected func() []byte
+	}{
+		{name: "exact", in: "Countries110", expected: Countries110},
+		{name: "lower case", in: "cities10", expected: Cities10},
+		{name: "spaces", in: " Provinces10 ", expected: Provinces10},
+		{name: "unknown", in: "Countries50"},
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			d, ok := DatasetByName(test.in)
+			if ok != (test.expected != nil) {
+				t.Fatalf("expected found: %v\n got: %v\n", test.expected != nil, ok)
+			}
+
+			if ok && !bytes.Equal(d(), test.expected()) {
+				t.Errorf("wrong dataset for %q", test.in)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+// ErrNoGeometry is returned when encoding the geometry of a Location that
+// doesn't have one, see WithGeometry.
+var ErrNoGeometry = errors.New("no geometry")
+
+// WithGeometry keeps a reference to the polygon that each Location came
+// from, so that it can be got with Location.Geometry, e.g. to highlight it on
+// a map. This doesn't use any more memory for the polygons themselves.
+// Locations can still be compared with ==, but it compares the polygons by
+// identity rather than by value, so two Locations with the same fields aren't
+// always equal.
+//
+// It can be given to Load as well.
+func WithGeometry() Option {
+	return func(o *options) {
+		o.geometry = true
+	}
+}
+
+// Geometry returns the polygon that the Location came from, as a
+// *geom.Polygon or *geom.MultiPolygon with the rings wound as RFC 7946 says,
+// or nil if the Rgeo wasn't created using WithGeometry. Where several
+// features were matched it's the smallest of them, which is the most detailed,
+// e.g. the city rather than the province or the country it's in.
+func (l Location) Geometry() geom.T {
+	return l.SimplifiedGeometry(0)
+}
+
+// SimplifiedGeometry works like Geometry, but leaves out any vertices that
+// are closer than tolerance to the line between the ones either side of them,
+// using the Ramer–Douglas–Peucker algorithm on each ring. Rings that would be
+// simplified away entirely, such as small islands, are left out.
+func (l Location) SimplifiedGeometry(tolerance s1.Angle) geom.T {
+	if l.poly == nil {
+		return nil
+	}
+
+	return geometryFromPolygon(l.poly.p, tolerance)
+}
+
+// GeoJSON encodes the geometry of the Location, simplified by tolerance (which
+// can be zero), as a GeoJSON Feature with the Location fields as its
+// properties.
+func (l Location) GeoJSON(tolerance s1.Angle) ([]byte, error) {
+	g := l.SimplifiedGeometry(tolerance)
+	if g == nil {
+		return nil, ErrNoGeometry
+	}
+
+	// The properties are the same as the JSON encoding of the Location
+	b, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+
+	var props map[string]interface{}
+	if err := json.Unmarshal(b, &props); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&geojson.Feature{Geometry: g, Properties: props})
+}
+
+// WKT encodes the geometry of the Location, simplified by tolerance (which can
+// be zero), as Well Known Text.
+func (l Location) WKT(tolerance s1.Angle) (string, error) {
+	g := l.SimplifiedGeometry(tolerance)
+	if g == nil {
+		return "", ErrNoGeometry
+	}
+
+	return wkt.Marshal(g)
+}
+
+// polygon is the polygon of a Location, see WithGeometry. Like properties it's
+// kept behind a pointer, with the area worked out when it's first needed.
+type polygon struct {
+	p *s2.Polygon
+
+	areaOnce sync.Once
+	area     float64
+}
+
+// smaller returns whichever of p and other has the smaller area.
+func (p *polygon) smaller(other *polygon) *polygon {
+	switch {
+	case other == nil:
+		return p
+	case p == nil:
+		return other
+	}
+
+	for _, q := range []*polygon{p, other} {
+		q.areaOnce.Do(func() {
+			q.area = q.p.Area()
+		})
+	}
+
+	if other.area < p.area {
+		return other
+	}
+
+	return p
+}
+
+// geometryFromPolygon converts an s2 Polygon back to a geom Polygon, or a
+// MultiPolygon if it has more than one shell.
+func geometryFromPolygon(p *s2.Polygon, tolerance s1.Angle) geom.T {
+	var coords [][][]geom.Coord
+
+	// Index in coords of each shell, by loop index
+	shells := make(map[int]int)
+
+	// Loops containing the current one. The loops are in pre-order, so this
+	// finds the parents without Polygon.Parent, which gets them wrong.
+	var ancestors []int
+
+	for i, loop := range p.Loops() {
+		for len(ancestors) > 0 && p.LastDescendant(ancestors[len(ancestors)-1]) < i {
+			ancestors = ancestors[:len(ancestors)-1]
+		}
+
+		parent := -1
+		if len(ancestors) > 0 {
+			parent = ancestors[len(ancestors)-1]
+		}
+
+		ancestors = append(ancestors, i)
+
+		vs := simplifyLoop(loop.Vertices(), tolerance)
+		if vs == nil {
+			continue
+		}
+
+		ring := make([]geom.Coord, 0, len(vs)+1)
+		for _, v := range vs {
+			ring = append(ring, coordFromPoint(v))
+		}
+
+		ring = append(ring, ring[0])
+
+		if !loop.IsHole() {
+			shells[i] = len(coords)
+			coords = append(coords, [][]geom.Coord{ring})
+
+			continue
+		}
+
+		// s2 holes go around the hole anticlockwise, whereas RFC 7946 holes
+		// go clockwise. They're left out if their shell was.
+		if j, ok := shells[parent]; ok {
+			slices.Reverse(ring)
+			coords[j] = append(coords[j], ring)
+		}
+	}
+
+	if len(coords) == 1 {
+		return geom.NewPolygon(geom.XY).MustSetCoords(coords[0])
+	}
+
+	return geom.NewMultiPolygon(geom.XY).MustSetCoords(coords)
+}
+
+// simplifyLoop simplifies the vertices of a loop, returning nil if there
+// aren't enough left to make a loop.
+func simplifyLoop(vs []s2.Point, tolerance s1.Angle) []s2.Point {
+	if tolerance <= 0 {
+		return vs
+	}
+
+	// Split the loop into two lines at the vertex furthest from the first,
+	// which are always kept
+	far := 0
+
+	for i := range vs {
+		if vs[0].Distance(vs[i]) > vs[0].Distance(vs[far]) {
+			far = i
+		}
+	}
+
+	keep := make([]bool, len(vs))
+	keep[0], keep[far] = true, true
+
+	simplifyLine(vs, 0, far, tolerance, keep)
+	simplifyLine(vs, far, len(vs), tolerance, keep)
+
+	var ret []s2.Point
+
+	for i, v := range vs {
+		if keep[i] {
+			ret = append(ret, v)
+		}
+	}
+
+	if len(ret) < 3 {
+		return nil
+	}
+
+	return ret
+}
+
+// simplifyLine marks the vertices between a and b (which wraps around to the
+// start of the loop) that need keeping.
+func simplifyLine(vs []s2.Point, a, b int, tolerance s1.Angle, keep []bool) {
+	if b-a < 2 {
+		return
+	}
+
+	start, end := vs[a], vs[b%len(vs)]
+	furthest, dist := -1, tolerance
+
+	for i := a + 1; i < b; i++ {
+		if d := s2.DistanceFromSegment(vs[i], start, end); d > dist {
+			furthest, dist = i, d
+		}
+	}
+
+	if furthest < 0 {
+		return
+	}
+
+	keep[furthest] = true
+
+	simplifyLine(vs, a, furthest, tolerance, keep)
+	simplifyLine(vs, furthest, b, tolerance, keep)
+}
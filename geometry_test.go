@@ -0,0 +1,197 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/golang/geo/s1"
+	"github.com/twpayne/go-geom/encoding/wkt"
+)
+
+func TestGeometry(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[
+						[[0,0],[2,0.001],[4,0],[4,4],[0,4],[0,0]],
+						[[1,1],[1,2],[2,2],[2,1],[1,1]]
+					]}}
+			]
+		}`
+
+	testcity := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"name_conve":"Testville"},
+				"geometry":{"type":"MultiPolygon",
+					"coordinates":[
+						[[[3,3],[3.5,3],[3.5,3.5],[3,3.5],[3,3]]],
+						[[[0.5,3],[1,3],[1,3.5],[0.5,3.5],[0.5,3]]]
+					]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name      string
+		in        []float64
+		tolerance s1.Angle
+		expected  string
+	}{
+		{
+			name:     "hole",
+			in:       []float64{0.5, 0.5},
+			expected: "POLYGON ((4 0, 4 4, 0 4, 0 0, 2 0.001, 4 0), (2 2, 2 1, 1 1, 1 2, 2 2))",
+		},
+		{
+			name:      "simplified",
+			in:        []float64{0.5, 0.5},
+			tolerance: s1.Degree / 100,
+			expected:  "POLYGON ((4 0, 4 4, 0 4, 0 0, 4 0), (2 2, 2 1, 1 1, 1 2, 2 2))",
+		},
+		{
+			name:      "hole simplified away",
+			in:        []float64{0.5, 0.5},
+			tolerance: 2 * s1.Degree,
+			expected:  "POLYGON ((4 0, 4 4, 0 4, 0 0, 4 0))",
+		},
+		{
+			name: "smallest",
+			in:   []float64{3.2, 3.2},
+			expected: "MULTIPOLYGON (((3 3.5, 3 3, 3.5 3, 3.5 3.5, 3 3.5)), " +
+				"((0.5 3.5, 0.5 3, 1 3, 1 3.5, 0.5 3.5)))",
+		},
+	}
+
+	r, err := NewWithOptions(
+		WithDatasets(
+			func() []byte { return compressData(t, testgeo) },
+			func() []byte { return compressData(t, testcity) },
+		),
+		WithGeometry(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			loc, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			g := loc.SimplifiedGeometry(test.tolerance)
+			if g == nil {
+				t.Fatal("expected geometry")
+			}
+
+			// Converting to s2 and back leaves some noise in the coordinates
+			flat := g.FlatCoords()
+			for i := range flat {
+				flat[i] = math.Round(flat[i]*1e6) / 1e6
+			}
+
+			res, err := wkt.Marshal(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := deep.Equal(test.expected, res); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ADMIN":"Testland","ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}
+			]
+		}`
+
+	r, err := NewWithOptions(
+		WithDatasets(func() []byte { return compressData(t, testgeo) }),
+		WithGeometry(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc, err := r.ReverseGeocode([]float64{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Use a tolerance that leaves the square as it is but gets rid of the
+	// noise in the coordinates
+	b, err := loc.GeoJSON(s1.Degree / 1e3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var f struct {
+		Type       string
+		Geometry   struct{ Type string }
+		Properties map[string]string
+	}
+
+	if err := json.Unmarshal(b, &f); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Type != "Feature" || f.Geometry.Type != "Polygon" {
+		t.Errorf("expected Polygon Feature, got: %s", b)
+	}
+
+	expected := map[string]string{"country": "Testland", "country_code_3": "TST"}
+	if diff := deep.Equal(expected, f.Properties); diff != nil {
+		t.Error(diff)
+	}
+
+	// Without WithGeometry
+	r, err = New(func() []byte { return compressData(t, testgeo) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loc, err = r.ReverseGeocode([]float64{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loc.Geometry() != nil {
+		t.Errorf("expected no geometry, got: %v", loc.Geometry())
+	}
+
+	if _, err := loc.WKT(0); !errors.Is(err, ErrNoGeometry) {
+		t.Errorf("expected error: %s\n got: %s\n", ErrNoGeometry, err)
+	}
+}
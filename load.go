@@ -0,0 +1,266 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+	"github.com/twpayne/go-geom/encoding/geojson"
+)
+
+// NewFromReaders works like New, but reads the datasets from the given
+// readers. Each reader can hold either plain or gzip compressed GeoJSON, which
+// is detected automatically. The GeoJSON is decoded one feature at a time, so
+// the whole dataset never needs to be held in memory.
+func NewFromReaders(readers ...io.Reader) (*Rgeo, error) {
+	return NewWithOptions(WithReaders(readers...))
+}
+
+// NewFromFiles works like NewFromReaders, but reads the datasets from the
+// files at the given paths.
+func NewFromFiles(paths ...string) (*Rgeo, error) {
+	return NewWithOptions(WithFiles(paths...))
+}
+
+// newRgeo returns an empty Rgeo ready for datasets to be added.
+func newRgeo(opts options) *Rgeo {
+	return &Rgeo{
+		index: s2.NewShapeIndex(),
+		locs:  make(map[s2.Shape]Match),
+		opts:  opts,
+	}
+}
+
+// addFile adds the dataset in the file at the given path.
+func (r *Rgeo) addFile(path string, i int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open dataset %d: %w", i, err)
+	}
+
+	defer f.Close()
+
+	return r.addReader(f, i)
+}
+
+// addReader adds the dataset from the given reader, decompressing it first if
+// it starts with the gzip magic number.
+func (r *Rgeo) addReader(rd io.Reader, i int) error {
+	br := bufio.NewReader(rd)
+
+	magic, err := br.Peek(2)
+	if len(magic) == 0 {
+		if err == nil || errors.Is(err, io.EOF) {
+			return fmt.Errorf("no data in dataset %d", i)
+		}
+
+		return fmt.Errorf("failed to read dataset %d: %w", i, err)
+	}
+
+	if len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return r.addGzip(br, i)
+	}
+
+	return r.addGeoJSON(br, i)
+}
+
+// addGzip adds the dataset from the given reader of gzip compressed GeoJSON.
+func (r *Rgeo) addGzip(rd io.Reader, i int) error {
+	zr, err := gzip.NewReader(rd)
+	if err != nil {
+		return fmt.Errorf("decompression failed for dataset %d: %w", i, err)
+	}
+
+	if err := r.addGeoJSON(zr, i); err != nil {
+		return err
+	}
+
+	if err := zr.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip reader for dataset %d: %w", i, err)
+	}
+
+	return nil
+}
+
+// addGeoJSON adds the features from the given reader of GeoJSON to the index.
+func (r *Rgeo) addGeoJSON(rd io.Reader, i int) error {
+	var j int
+
+	err := decodeFeatures(rd, func(f *geojson.Feature) error {
+		// Points are populated places, which are kept apart from the polygons
+		if pt, ok := f.Geometry.(*geom.Point); ok && r.opts.places {
+			r.addPlace(pt, f.Properties)
+			j++
+
+			return nil
+		}
+
+		// The s2 ContainsPointQuery returns the shapes that contain the given
+		// point, but I haven't found any way to attach the location
+		// information to the shapes, so I use a map to get the information.
+		loc := r.opts.mapping.Location(f.Properties)
+		if r.opts.properties {
+			loc.props = newProperties(f.Properties, r.opts.propertyKeys)
+		}
+
+		if len(r.opts.languages) > 0 {
+			loc.names = newLocalNames(f.Properties, loc, r.opts.mapping, r.opts.languages)
+		}
+
+		m := Match{
+			Location: loc,
+			Dataset:  i,
+			Feature:  j,
+		}
+
+		j++
+
+		// Convert GeoJSON features from geom (multi)polygons to s2 polygons
+		c := conversion{
+			oriented: r.opts.oriented,
+			repair:   r.opts.validation == ValidationRepair,
+			validate: r.opts.validation != ValidationNone,
+		}
+
+		p, err := polygonFromGeometry(f.Geometry, &c)
+		if err == nil && r.opts.validation != ValidationNone {
+			err = validatePolygon(p)
+		}
+
+		if err != nil {
+			return r.invalidFeature(m, err)
+		}
+
+		for _, reason := range c.repairs {
+			r.issues = append(r.issues, newValidationIssue(m, reason, false))
+		}
+
+		if r.opts.geometry {
+			m.Location.poly = &polygon{p: p}
+		}
+
+		r.index.Add(p)
+		r.locs[p] = m
+
+		if r.opts.worldview != "" {
+			r.addWorldview(p, loc, f.Properties)
+		}
+
+		return nil
+	})
+
+	var jsonErr *jsonError
+	if errors.As(err, &jsonErr) {
+		return fmt.Errorf("invalid JSON in dataset %d: %w", i, jsonErr.err)
+	}
+
+	return err
+}
+
+// jsonError marks errors from decoding the GeoJSON itself, rather than from
+// handling the features.
+type jsonError struct {
+	err error
+}
+
+func (e *jsonError) Error() string { return e.err.Error() }
+
+func (e *jsonError) Unwrap() error { return e.err }
+
+// decodeFeatures decodes a GeoJSON FeatureCollection from the given reader,
+// calling fn with each feature as it is read, rather than decoding the whole
+// collection at once.
+func decodeFeatures(rd io.Reader, fn func(*geojson.Feature) error) error {
+	dec := json.NewDecoder(rd)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	var typ string
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return &jsonError{err}
+		}
+
+		switch tok {
+		case "type":
+			if err := dec.Decode(&typ); err != nil {
+				return &jsonError{err}
+			}
+		case "features":
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+
+			for dec.More() {
+				var f geojson.Feature
+				if err := dec.Decode(&f); err != nil {
+					return &jsonError{err}
+				}
+
+				if err := fn(&f); err != nil {
+					return err
+				}
+			}
+
+			if err := expectDelim(dec, ']'); err != nil {
+				return err
+			}
+		default:
+			// Skip anything else, such as bbox or crs
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return &jsonError{err}
+			}
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+
+	if typ != "FeatureCollection" {
+		return &jsonError{geojson.ErrUnsupportedType(typ)}
+	}
+
+	return nil
+}
+
+// expectDelim reads the next token from dec and checks that it is the given
+// delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return &jsonError{err}
+	}
+
+	if tok != delim {
+		return &jsonError{fmt.Errorf("expected %s but got %v", delim, tok)}
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+const testLoadGeo = `{
+	"type":"FeatureCollection",
+	"bbox":[0,52,1,53],
+	"features":[
+		{"type":"Feature",
+		"properties":{"ISO_A3":"TST"},
+		"geometry":{"type":"Polygon",
+			"coordinates":[[[0,52],[1,52],[1,53],[0,53],[0,52]]]}}
+	]
+}`
+
+func TestNewFromReaders(t *testing.T) {
+	testdata := []struct {
+		name string
+		in   func() io.Reader
+	}{
+		{
+			name: "plain",
+			in:   func() io.Reader { return strings.NewReader(testLoadGeo) },
+		},
+		{
+			name: "gzip",
+			in:   func() io.Reader { return bytes.NewReader(compressData(t, testLoadGeo)) },
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewFromReaders(test.in())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := r.ReverseGeocode([]float64{0.5, 52.5})
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(Location{CountryCode3: "TST"}, result); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestNewFromFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "plain.geojson")
+	if err := os.WriteFile(plain, []byte(testLoadGeo), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed := filepath.Join(dir, "compressed.gz")
+	if err := os.WriteFile(compressed, compressData(t, testLoadGeo), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewFromFiles(plain, compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := r.ReverseGeocodeAll([]float64{0.5, 52.5})
+	if err != nil {
+		t.Error(err)
+	}
+
+	expected := []Match{
+		{Location: Location{CountryCode3: "TST"}, Dataset: 0, Feature: 0},
+		{Location: Location{CountryCode3: "TST"}, Dataset: 1, Feature: 0},
+	}
+	if diff := deep.Equal(expected, matches); diff != nil {
+		t.Error(diff)
+	}
+
+	if _, err := NewFromFiles(filepath.Join(dir, "missing.geojson")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestNewFromReaders_BadData(t *testing.T) {
+	testdata := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{
+			name: "Empty data",
+			in:   ``,
+			err:  "no data in dataset 0",
+		},
+		{
+			name: "Bad JSON",
+			in:   `this is not JSON`,
+			err:  "invalid JSON in dataset 0: invalid character 'h' in literal true (expecting 'r')",
+		},
+		{
+			name: "Not an object",
+			in:   `[]`,
+			err:  "invalid JSON in dataset 0: expected { but got [",
+		},
+		{
+			name: "Wrong type",
+			in:   `{"type":"Feature","features":[]}`,
+			err:  "invalid JSON in dataset 0: geojson: unsupported type: Feature",
+		},
+		{
+			name: "Wrong geometry",
+			in: `{"type":"FeatureCollection","features":
+					[{"type":"Feature","geometry":
+						{"type":"Point","coordinates":[0,0]}}]}`,
+			err: "bad polygon in geometry of feature 0 in dataset 0: needs Polygon or MultiPolygon",
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewFromReaders(strings.NewReader(test.in))
+			if err == nil || err.Error() != test.err {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestReverseGeocode_Marine(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}
+			]
+		}`
+
+	// The sea overlaps the coastline, like the Natural Earth marine polygons
+	testsea := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"water_body":"Test Sea"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1,0],[4,0],[4,2],[1,2],[1,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected string
+		err      error
+	}{
+		{
+			name:     "Sea",
+			in:       []float64{3, 1},
+			expected: "Test Sea",
+		},
+		{
+			name: "On land",
+			in:   []float64{1.5, 1},
+		},
+		{
+			name: "Outside",
+			in:   []float64{10, 10},
+			err:  ErrLocationNotFound,
+		},
+	}
+
+	r, err := New(
+		func() []byte { return compressData(t, testgeo) },
+		func() []byte { return compressData(t, testsea) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			loc, err := r.ReverseGeocode(test.in)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+			if loc.WaterBody != test.expected {
+				t.Errorf("expected: %q\n got: %q\n", test.expected, loc.WaterBody)
+			}
+			if err == nil && (loc.CountryCode3 == "") == (test.expected == "") {
+				t.Errorf("expected either a country or a water body, got: %v", loc)
+			}
+		})
+	}
+}
+
+func TestReverseGeocode_LandWins(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"water_body":"Test Sea"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"tzid":"Etc/UTC"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST","CONTINENT":"Testinent"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1,0],[2,0],[2,2],[1,2],[1,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected Location
+		str      string
+	}{
+		{
+			name:     "sea",
+			in:       []float64{0.5, 1},
+			expected: Location{WaterBody: "Test Sea", TimeZone: "Etc/UTC"},
+			str:      "<Location> Test Sea,",
+		},
+		{
+			name: "land",
+			in:   []float64{1.5, 1},
+			expected: Location{
+				CountryCode3: "TST",
+				Continent:    "Testinent",
+				TimeZone:     "Etc/UTC",
+			},
+			str: "<Location> (TST), Testinent",
+		},
+	}
+
+	r, err := NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			loc, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(test.expected, loc); diff != nil {
+				t.Error(diff)
+			}
+			if loc.String() != test.str {
+				t.Errorf("expected: %s\n got: %s\n", test.str, loc)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestReverseGeocode_Maritime(t *testing.T) {
+	// Laid out like the Marine Regions datasets, with the territorial sea
+	// given before the EEZ and the land polygon overlapping the coast
+	land := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}
+			]
+		}`
+	territorial := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_SOV1":"TST","POL_TYPE":"12NM"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1.5,0],[2.5,0],[2.5,2],[1.5,2],[1.5,0]]]}}
+			]
+		}`
+	eez := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_SOV1":"TST","POL_TYPE":"200NM"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1.5,0],[5,0],[5,2],[1.5,2],[1.5,0]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_SOV1":"TSU","POL_TYPE":"200NM"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[5,0],[8,0],[8,2],[5,2],[5,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected Location
+	}{
+		{
+			name:     "inland",
+			in:       []float64{0.5, 1},
+			expected: Location{CountryCode3: "TST"},
+		},
+		{
+			name: "coast",
+			in:   []float64{1.75, 1},
+			expected: Location{
+				CountryCode3:         "TST",
+				MaritimeCountryCode3: "TST",
+				MaritimeZoneType:     "12NM",
+			},
+		},
+		{
+			name: "territorial sea",
+			in:   []float64{2.25, 1},
+			expected: Location{
+				MaritimeCountryCode3: "TST",
+				MaritimeZoneType:     "12NM",
+			},
+		},
+		{
+			name: "EEZ",
+			in:   []float64{6, 1},
+			expected: Location{
+				MaritimeCountryCode3: "TSU",
+				MaritimeZoneType:     "200NM",
+			},
+		},
+	}
+
+	r, err := NewFromReaders(strings.NewReader(land),
+		strings.NewReader(territorial), strings.NewReader(eez))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			loc, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(test.expected, loc); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import "strings"
+
+// WithLanguages keeps the names of countries, provinces and cities in the
+// given languages, so that they can be used with Location.Localized. The
+// languages are BCP 47 tags such as "de", "pt-BR" or "zh-Hant", although
+// Natural Earth only has names by language and not by region, with the
+// exception of Traditional Chinese.
+//
+// Which properties the names come from is set by the Localized fields of the
+// PropertyMapping. Locations can still be compared with ==, but it compares the
+// names by identity rather than by value, so two Locations with the same fields
+// aren't always equal.
+func WithLanguages(langs ...string) Option {
+	return func(o *options) {
+		o.languages = o.languages[:0]
+
+		for _, lang := range langs {
+			o.languages = append(o.languages, languageCode(lang))
+		}
+	}
+}
+
+// Localized returns a copy of the Location with the Country, Province and City
+// names in the given language, which is a BCP 47 tag like those given to
+// WithLanguages. Any names that aren't available in that language are left in
+// English, as are all of them if the language wasn't given to WithLanguages.
+func (l Location) Localized(lang string) Location {
+	if l.names == nil {
+		return l
+	}
+
+	n := l.names.m[languageCode(lang)]
+
+	l.Country = firstNonEmpty(n.Country, l.Country)
+	l.Province = firstNonEmpty(n.Province, l.Province)
+	l.City = firstNonEmpty(n.City, l.City)
+
+	return l
+}
+
+// localNames holds the localized names of a Location, by language code. Like
+// properties it's kept behind a pointer so that == still compiles for
+// Location, but it compares the pointers rather than the names, see
+// WithLanguages.
+type localNames struct {
+	m map[string]localName
+}
+
+// localName is the names of a Location in a single language.
+type localName struct {
+	Country  string `json:"country,omitempty"`
+	Province string `json:"province,omitempty"`
+	City     string `json:"city,omitempty"`
+}
+
+// newLocalNames gets the localized names in each of the given languages from
+// the GeoJSON properties. Each name is only looked for when loc has the
+// English name, so that e.g. the country names merged into the provinces
+// dataset aren't mistaken for province names.
+func newLocalNames(p map[string]interface{}, loc Location, m PropertyMapping,
+	langs []string,
+) *localNames {
+	names := make(map[string]localName, len(langs))
+
+	for _, lang := range langs {
+		var n localName
+
+		if loc.Country != "" {
+			n.Country = getPropertyString(p, localizedKeys(m.LocalizedCountry, lang)...)
+		}
+
+		if loc.Province != "" {
+			n.Province = getPropertyString(p, localizedKeys(m.LocalizedProvince, lang)...)
+		}
+
+		if loc.City != "" {
+			n.City = getPropertyString(p, localizedKeys(m.LocalizedCity, lang)...)
+		}
+
+		if n != (localName{}) {
+			names[lang] = n
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	return &localNames{m: names}
+}
+
+// merge returns the names from both, where the names in n take precedence
+// over those in other, the same as the other Location fields.
+func (n *localNames) merge(other *localNames) *localNames {
+	switch {
+	case other == nil:
+		return n
+	case n == nil:
+		return other
+	}
+
+	m := make(map[string]localName, len(n.m)+len(other.m))
+
+	for lang, o := range other.m {
+		m[lang] = o
+	}
+
+	for lang, this := range n.m {
+		o := m[lang]
+
+		m[lang] = localName{
+			Country:  firstNonEmpty(this.Country, o.Country),
+			Province: firstNonEmpty(this.Province, o.Province),
+			City:     firstNonEmpty(this.City, o.City),
+		}
+	}
+
+	return &localNames{m: m}
+}
+
+// withCountry returns the names with the country names replaced by those in c,
+// see Location.withCountry.
+func (n *localNames) withCountry(c *localNames) *localNames {
+	m := make(map[string]localName)
+
+	if n != nil {
+		for lang, this := range n.m {
+			if this.Province != "" || this.City != "" {
+				m[lang] = localName{Province: this.Province, City: this.City}
+			}
+		}
+	}
+
+	if c != nil {
+		for lang, that := range c.m {
+			if that.Country != "" {
+				this := m[lang]
+				this.Country = that.Country
+				m[lang] = this
+			}
+		}
+	}
+
+	if len(m) == 0 {
+		return nil
+	}
+
+	return &localNames{m: m}
+}
+
+// localizedKeys fills in the language code in each of the keys, where {lang}
+// is replaced with the code in lower case and {LANG} in upper case.
+func localizedKeys(keys []string, lang string) []string {
+	r := strings.NewReplacer("{lang}", lang, "{LANG}", strings.ToUpper(lang))
+	ret := make([]string, len(keys))
+
+	for i, k := range keys {
+		ret[i] = r.Replace(k)
+	}
+
+	return ret
+}
+
+// languageCode converts a BCP 47 language tag to the lower case language code
+// used in the Natural Earth property names. This is just the language subtag,
+// except for Traditional Chinese, which Natural Earth calls "zht".
+func languageCode(tag string) string {
+	subtags := strings.Split(strings.ToLower(strings.ReplaceAll(tag, "_", "-")), "-")
+
+	if subtags[0] == "zh" {
+		for _, s := range subtags[1:] {
+			switch s {
+			case "hant", "tw", "hk", "mo":
+				return "zht"
+			case "hans":
+				return "zh"
+			}
+		}
+	}
+
+	return subtags[0]
+}
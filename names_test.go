@@ -0,0 +1,148 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestLocalized(t *testing.T) {
+	// Laid out like Provinces10, with the country names merged into the
+	// province
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"admin":"Testland","ISO_A3":"TST","name":"Test Province",
+					"NAME_DE":"Testreich","NAME_FR":"Testlande","NAME_ZHT":"測試國",
+					"name_de":"Testprovinz"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"name_conve":"Testville","name_de":"Teststadt"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0.5,0.5],[1,0.5],[1,1],[0.5,1],[0.5,0.5]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		langs    []string
+		lang     string
+		in       []float64
+		expected Location
+	}{
+		{
+			name:  "german",
+			langs: []string{"de", "fr"},
+			lang:  "de-AT",
+			in:    []float64{0.75, 0.75},
+			expected: Location{
+				Country:      "Testreich",
+				CountryCode3: "TST",
+				Province:     "Testprovinz",
+				City:         "Teststadt",
+			},
+		},
+		{
+			name:  "fallback to english",
+			langs: []string{"de", "fr"},
+			lang:  "fr",
+			in:    []float64{0.75, 0.75},
+			expected: Location{
+				Country:      "Testlande",
+				CountryCode3: "TST",
+				Province:     "Test Province",
+				City:         "Testville",
+			},
+		},
+		{
+			name:  "traditional chinese",
+			langs: []string{"zh-Hant"},
+			lang:  "zh_TW",
+			in:    []float64{1.5, 1.5},
+			expected: Location{
+				Country:      "測試國",
+				CountryCode3: "TST",
+				Province:     "Test Province",
+			},
+		},
+		{
+			name:  "not loaded",
+			langs: []string{"de"},
+			lang:  "fr",
+			in:    []float64{1.5, 1.5},
+			expected: Location{
+				Country:      "Testland",
+				CountryCode3: "TST",
+				Province:     "Test Province",
+			},
+		},
+		{
+			name: "without WithLanguages",
+			lang: "de",
+			in:   []float64{1.5, 1.5},
+			expected: Location{
+				Country:      "Testland",
+				CountryCode3: "TST",
+				Province:     "Test Province",
+			},
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewWithOptions(
+				WithReaders(strings.NewReader(testgeo)),
+				WithLanguages(test.langs...),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if diff := deep.Equal(test.expected, result.Localized(test.lang)); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestLanguageCode(t *testing.T) {
+	for in, expected := range map[string]string{
+		"de":         "de",
+		"DE":         "de",
+		"pt-BR":      "pt",
+		"zh":         "zh",
+		"zh-Hans-HK": "zh",
+		"zh-Hant":    "zht",
+		"zh_TW":      "zht",
+		"":           "",
+	} {
+		if result := languageCode(in); result != expected {
+			t.Errorf("%q: expected: %q\n got: %q\n", in, expected, result)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// ReverseGeocodeNearest works like ReverseGeocode, except that when the given
+// coordinate isn't inside any of the polygons it returns the Location of the
+// closest one instead, as long as it is no further away than maxDistance. This
+// is useful for points just off the coast or in the small gaps between
+// neighbouring polygons.
+//
+// The returned angle is the great circle distance to the matched polygon,
+// which is zero if the coordinate is inside it. To convert it to a distance
+// on the Earth's surface multiply the radians by the Earth's radius.
+func (r *Rgeo) ReverseGeocodeNearest(loc geom.Coord, maxDistance s1.Angle) (Location, s1.Angle, error) {
+	if l, err := r.ReverseGeocode(loc); err == nil {
+		return l, 0, nil
+	}
+
+	res := r.closestEdges(pointFromCoord(loc), maxDistance, 1)
+	if len(res) == 0 {
+		return Location{}, 0, ErrLocationNotFound
+	}
+
+	return r.locs[r.index.Shape(res[0].ShapeID())].Location, res[0].Distance().Angle(), nil
+}
+
+// closestEdges returns up to n of the polygon edges closest to the given
+// point that are no further away than maxDistance, sorted by distance. A
+// negative n returns all of them.
+func (r *Rgeo) closestEdges(p s2.Point, maxDistance s1.Angle, n int) []s2.EdgeQueryResult {
+	opts := s2.NewClosestEdgeQueryOptions().
+		IncludeInteriors(false).
+		DistanceLimit(s1.ChordAngleFromAngle(maxDistance).Successor())
+
+	if n > 0 {
+		opts = opts.MaxResults(n)
+	}
+
+	query := s2.NewClosestEdgeQuery(r.index, opts)
+
+	return query.FindEdges(s2.NewMinDistanceToPointTarget(p))
+}
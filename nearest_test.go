@@ -0,0 +1,104 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/golang/geo/s1"
+)
+
+func TestReverseGeocodeNearest(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TSU"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[3,0],[4,0],[4,1],[3,1],[3,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		max      s1.Angle
+		err      error
+		expected Location
+		distance s1.Angle
+	}{
+		{
+			name:     "inside",
+			in:       []float64{0.5, 0.5},
+			max:      s1.Degree,
+			err:      nil,
+			expected: Location{CountryCode3: "TST"},
+			distance: 0,
+		},
+		{
+			name:     "near first",
+			in:       []float64{1.5, 0.5},
+			max:      s1.Degree,
+			err:      nil,
+			expected: Location{CountryCode3: "TST"},
+			distance: s1.Degree / 2,
+		},
+		{
+			name:     "nearer second",
+			in:       []float64{2.75, 0.5},
+			max:      s1.Degree,
+			err:      nil,
+			expected: Location{CountryCode3: "TSU"},
+			distance: s1.Degree / 4,
+		},
+		{
+			name:     "too far",
+			in:       []float64{1.5, 0.5},
+			max:      s1.Degree / 4,
+			err:      ErrLocationNotFound,
+			expected: Location{},
+			distance: 0,
+		},
+	}
+
+	r, err := New(func() []byte { return compressData(t, testgeo) })
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			result, distance, err := r.ReverseGeocodeNearest(test.in, test.max)
+			if err != test.err {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+			if diff := deep.Equal(test.expected, result); diff != nil {
+				t.Error(diff)
+			}
+			if math.Abs((distance - test.distance).Degrees()) > 1e-3 {
+				t.Errorf("expected distance: %s\n got: %s\n", test.distance, distance)
+			}
+		})
+	}
+}
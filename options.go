@@ -0,0 +1,265 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Option configures NewWithOptions.
+type Option func(*options)
+
+// options holds the configuration built up from the Options given to
+// NewWithOptions.
+type options struct {
+	sources []source
+	mapping PropertyMapping
+
+	// Whether to keep the raw GeoJSON properties, and which ones
+	properties   bool
+	propertyKeys []string
+
+	// Level of the cell cache, or 0 for no cache
+	cellLevel int
+
+	// Whether to trust the winding order of the polygon rings
+	oriented bool
+
+	// What to do with invalid geometry
+	validation ValidationMode
+
+	// Language codes of the localized names to keep
+	languages []string
+
+	// Suffix of the Natural Earth point of view properties to use
+	worldview string
+
+	// Whether to keep the polygon of each Location
+	geometry bool
+
+	// Whether to read Point features as populated places
+	places bool
+}
+
+// source adds a single dataset to r, where i is the index of the dataset.
+type source func(r *Rgeo, i int) error
+
+// NewWithOptions returns an Rgeo struct configured with the given options,
+// which can then be used with ReverseGeocode. The datasets are added with
+// WithDatasets, WithReaders and WithFiles, and are numbered in the order they
+// are given across all of those options.
+func NewWithOptions(opts ...Option) (*Rgeo, error) {
+	o := options{mapping: NaturalEarthMapping()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	sources := o.sources
+	o.sources = nil
+
+	ret := newRgeo(o)
+
+	for i, src := range sources {
+		if err := src(ret, i); err != nil {
+			return nil, err
+		}
+	}
+
+	ret.applyWorldview()
+
+	return ret, nil
+}
+
+// WithDatasets adds datasets in the same format as New, e.g. Countries110.
+func WithDatasets(datasets ...func() []byte) Option {
+	return func(o *options) {
+		for _, dataset := range datasets {
+			dataset := dataset
+
+			o.sources = append(o.sources, func(r *Rgeo, i int) error {
+				br := bytes.NewReader(dataset())
+				if br.Len() == 0 {
+					return fmt.Errorf("no data in dataset %d", i)
+				}
+
+				return r.addGzip(br, i)
+			})
+		}
+	}
+}
+
+// WithReaders adds datasets read from the given readers, in the same way as
+// NewFromReaders.
+func WithReaders(readers ...io.Reader) Option {
+	return func(o *options) {
+		for _, rd := range readers {
+			rd := rd
+
+			o.sources = append(o.sources, func(r *Rgeo, i int) error {
+				return r.addReader(rd, i)
+			})
+		}
+	}
+}
+
+// WithFiles adds datasets read from the files at the given paths, in the same
+// way as NewFromFiles.
+func WithFiles(paths ...string) Option {
+	return func(o *options) {
+		for _, path := range paths {
+			path := path
+
+			o.sources = append(o.sources, func(r *Rgeo, i int) error {
+				return r.addFile(path, i)
+			})
+		}
+	}
+}
+
+// WithPropertyMapping sets which GeoJSON properties are used to fill in each
+// Location field, for datasets that don't use the Natural Earth property names.
+// It applies to every dataset, so to mix Natural Earth data with your own add
+// both sets of keys to the mapping.
+func WithPropertyMapping(m PropertyMapping) Option {
+	return func(o *options) {
+		o.mapping = m
+	}
+}
+
+// WithProperties keeps the raw GeoJSON properties of each feature, so that
+// they can be read from the returned Locations with Location.Property. If any
+// keys are given only those properties are kept, otherwise all of them are,
+// which uses a fair bit more memory. Locations can still be compared with ==,
+// but it compares the properties by identity rather than by value, so two
+// Locations with the same fields aren't always equal.
+func WithProperties(keys ...string) Option {
+	return func(o *options) {
+		o.properties = true
+		o.propertyKeys = keys
+	}
+}
+
+// WithRFC7946Winding trusts the winding order of the polygon rings, as
+// specified by RFC 7946: exterior rings are counterclockwise and holes are
+// clockwise. This allows polygons that cover more than half of the sphere.
+// Without it, every ring is assumed to enclose less than half of the sphere and
+// the order of its coordinates is ignored, which is needed for the included
+// datasets as older GeoJSON didn't specify a winding order.
+func WithRFC7946Winding() Option {
+	return func(o *options) {
+		o.oriented = true
+	}
+}
+
+// PropertyMapping lists the GeoJSON property keys used to fill in each field of
+// a Location. Each field takes the value of the first key in its list that is
+// present as a string in the properties, like a chain of fallbacks. The codes
+// also fall back past the "-99" Natural Earth uses for a missing code, e.g.
+// France's ISO_A2 and ISO_A3.
+type PropertyMapping struct {
+	Country      []string
+	CountryLong  []string
+	CountryCode2 []string
+	CountryCode3 []string
+	Continent    []string
+	Region       []string
+	SubRegion    []string
+	Province     []string
+	ProvinceCode []string
+	District     []string
+	City         []string
+	TimeZone     []string
+	WaterBody    []string
+
+	MaritimeCountryCode3 []string
+	MaritimeZoneType     []string
+
+	// Keys of the name and population of the populated places, which are
+	// read from features with Point geometries instead of the fields above,
+	// see NearestPlaces
+	Place      []string
+	Population []string
+
+	// Keys of the names in other languages, where {lang} and {LANG} are
+	// replaced with the language code in lower and upper case, see
+	// WithLanguages
+	LocalizedCountry  []string
+	LocalizedProvince []string
+	LocalizedCity     []string
+
+	// Suffix trimmed from the end of the city names, as some of the Natural
+	// Earth ones have a 2 on the end
+	CitySuffix string
+}
+
+// NaturalEarthMapping returns the PropertyMapping used by default, which
+// matches the included datasets. It's a new copy each time, so it can be
+// changed as a starting point for datasets with similar properties.
+func NaturalEarthMapping() PropertyMapping {
+	return PropertyMapping{
+		Country:      []string{"ADMIN", "admin"},
+		CountryLong:  []string{"FORMAL_EN"},
+		CountryCode2: []string{"ISO_A2", "ISO_A2_EH"},
+		CountryCode3: []string{"ISO_A3", "ISO_A3_EH"},
+		Continent:    []string{"CONTINENT"},
+		Region:       []string{"REGION_UN"},
+		SubRegion:    []string{"SUBREGION"},
+		Province:     []string{"name"},
+		ProvinceCode: []string{"iso_3166_2"},
+		District:     []string{"district"},
+		City:         []string{"name_conve"},
+		TimeZone:     []string{"tzid"},
+		WaterBody:    []string{"water_body"},
+
+		MaritimeCountryCode3: []string{"ISO_SOV1"},
+		MaritimeZoneType:     []string{"POL_TYPE"},
+
+		Place:      []string{"NAME", "name"},
+		Population: []string{"POP_MAX", "pop_max"},
+
+		LocalizedCountry:  []string{"NAME_{LANG}"},
+		LocalizedProvince: []string{"name_{lang}"},
+		LocalizedCity:     []string{"name_{lang}", "NAME_{LANG}"},
+
+		CitySuffix: "2",
+	}
+}
+
+// Location returns the Location for the given GeoJSON properties according to
+// the mapping.
+func (m PropertyMapping) Location(p map[string]interface{}) Location {
+	return Location{
+		Country:      getPropertyString(p, m.Country...),
+		CountryLong:  getPropertyString(p, m.CountryLong...),
+		CountryCode2: getPropertyCode(p, m.CountryCode2...),
+		CountryCode3: getPropertyCode(p, m.CountryCode3...),
+		Continent:    getPropertyString(p, m.Continent...),
+		Region:       getPropertyString(p, m.Region...),
+		SubRegion:    getPropertyString(p, m.SubRegion...),
+		Province:     getPropertyString(p, m.Province...),
+		ProvinceCode: getPropertyCode(p, m.ProvinceCode...),
+		District:     getPropertyString(p, m.District...),
+		City:         strings.TrimSuffix(getPropertyString(p, m.City...), m.CitySuffix),
+		TimeZone:     getPropertyString(p, m.TimeZone...),
+		WaterBody:    getPropertyString(p, m.WaterBody...),
+
+		MaritimeCountryCode3: getPropertyString(p, m.MaritimeCountryCode3...),
+		MaritimeZoneType:     getPropertyString(p, m.MaritimeZoneType...),
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestWithPropertyMapping(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"NAME_0":"Testland","GID_0":"TST","NAME_1":"Testshire"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,52],[1,52],[1,53],[0,53],[0,52]]]}},
+				{"type":"Feature",
+				"properties":{"ADMIN":"Otherland","ISO_A3":"OTH"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[2,52],[3,52],[3,53],[2,53],[2,52]]]}}
+			]
+		}`
+
+	mapping := PropertyMapping{
+		Country:      []string{"NAME_0", "ADMIN"},
+		CountryCode3: []string{"GID_0", "ISO_A3"},
+		Province:     []string{"NAME_1"},
+	}
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected Location
+	}{
+		{
+			name: "custom keys",
+			in:   []float64{0.5, 52.5},
+			expected: Location{
+				Country:      "Testland",
+				CountryCode3: "TST",
+				Province:     "Testshire",
+			},
+		},
+		{
+			name: "fallback keys",
+			in:   []float64{2.5, 52.5},
+			expected: Location{
+				Country:      "Otherland",
+				CountryCode3: "OTH",
+			},
+		},
+	}
+
+	r, err := NewWithOptions(
+		WithReaders(strings.NewReader(testgeo)),
+		WithPropertyMapping(mapping),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			result, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(test.expected, result); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestNewWithOptions_DatasetOrder(t *testing.T) {
+	r, err := NewWithOptions(
+		WithDatasets(func() []byte { return compressData(t, testLoadGeo) }),
+		WithReaders(strings.NewReader(testLoadGeo)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := r.ReverseGeocodeAll([]float64{0.5, 52.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, m := range matches {
+		if m.Dataset != i {
+			t.Errorf("expected dataset %d, got %d", i, m.Dataset)
+		}
+	}
+}
+
+func TestNaturalEarthMapping(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"name_conve":"Testville2","ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,52],[1,52],[1,53],[0,53],[0,52]]]}}
+			]
+		}`
+
+	expected := Location{City: "Testville", CountryCode3: "TST"}
+
+	// The default and an explicit NaturalEarthMapping give the same Location
+	for _, opts := range [][]Option{
+		{WithReaders(strings.NewReader(testgeo))},
+		{WithReaders(strings.NewReader(testgeo)), WithPropertyMapping(NaturalEarthMapping())},
+	} {
+		r, err := NewWithOptions(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := r.ReverseGeocode([]float64{0.5, 52.5})
+		if err != nil {
+			t.Error(err)
+		}
+		if diff := deep.Equal(expected, result); diff != nil {
+			t.Error(diff)
+		}
+	}
+
+	// Changing the returned mapping doesn't change the default
+	m := NaturalEarthMapping()
+	m.City[0] = "changed"
+
+	if c := NaturalEarthMapping().City[0]; c != "name_conve" {
+		t.Errorf("expected: name_conve\n got: %s\n", c)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// Place is a populated place, as returned by NearestPlaces.
+type Place struct {
+	Name       string `json:"name"`
+	Population int    `json:"population,omitempty"`
+
+	// Coordinates of the place, as longitude then latitude
+	Coord geom.Coord `json:"coord"`
+
+	// Great circle distance from the coordinate given to NearestPlaces, see
+	// ReverseGeocodeNearest for converting it to a distance on the Earth's
+	// surface
+	Distance s1.Angle `json:"distance"`
+
+	// Location of the place itself, from the polygon datasets
+	Location Location `json:"location"`
+}
+
+// WithPlaces reads the features with Point geometries, such as the Natural
+// Earth populated places, as populated places for NearestPlaces. Without it
+// they're bad geometry like any other feature that isn't a polygon, so that
+// stray points in a polygon dataset aren't taken as places by mistake, e.g.
+//
+//	NewWithOptions(WithDatasets(Countries10), WithFiles("Places10.gz"), WithPlaces())
+func WithPlaces() Option {
+	return func(o *options) {
+		o.places = true
+	}
+}
+
+// NearestPlaces returns up to k of the populated places closest to the given
+// coordinate that are no further away than maxDistance, closest first. A k of
+// zero or less returns all of them. The places come from GeoJSON features with
+// Point geometries, which are only read when using WithPlaces, see the README
+// for generating a dataset of them. ErrLocationNotFound is returned if there
+// aren't any within maxDistance.
+//
+// This is useful for labelling coordinates that aren't in any city, e.g. "5km
+// from Oxford".
+func (r *Rgeo) NearestPlaces(loc geom.Coord, k int, maxDistance s1.Angle) ([]Place, error) {
+	r.buildPlaces()
+
+	if r.placeIndex == nil {
+		return nil, ErrLocationNotFound
+	}
+
+	opts := s2.NewClosestEdgeQueryOptions().
+		DistanceLimit(s1.ChordAngleFromAngle(maxDistance).Successor())
+
+	if k > 0 {
+		opts = opts.MaxResults(k)
+	}
+
+	query := s2.NewClosestEdgeQuery(r.placeIndex, opts)
+
+	res := query.FindEdges(s2.NewMinDistanceToPointTarget(pointFromCoord(loc)))
+	if len(res) == 0 {
+		return nil, ErrLocationNotFound
+	}
+
+	places := make([]Place, len(res))
+
+	for i, e := range res {
+		places[i] = r.places[e.EdgeID()]
+		places[i].Distance = e.Distance().Angle()
+		places[i].Location, _ = r.ReverseGeocode(places[i].Coord)
+	}
+
+	return places, nil
+}
+
+// addPlace adds a populated place from a GeoJSON feature with a Point
+// geometry.
+func (r *Rgeo) addPlace(pt *geom.Point, p map[string]interface{}) {
+	r.places = append(r.places, Place{
+		Name:       getPropertyString(p, r.opts.mapping.Place...),
+		Population: int(getPropertyNumber(p, r.opts.mapping.Population...)),
+		Coord:      geom.Coord{pt.X(), pt.Y()},
+	})
+}
+
+// buildPlaces builds the index of the populated places, the first time it's
+// called.
+func (r *Rgeo) buildPlaces() {
+	r.placesOnce.Do(func() {
+		if len(r.places) == 0 {
+			return
+		}
+
+		// Each place is an edge of the PointVector, with the same index
+		points := make(s2.PointVector, len(r.places))
+		for i, p := range r.places {
+			points[i] = pointFromCoord(p.Coord)
+		}
+
+		r.placeIndex = s2.NewShapeIndex()
+		r.placeIndex.Add(&points)
+		r.placeIndex.Build()
+	})
+}
+
+// getPropertyNumber gets the number from a map given the key, or from the
+// next given key if the previous fails.
+func getPropertyNumber(m map[string]interface{}, keys ...string) float64 {
+	for _, k := range keys {
+		if f, ok := m[k].(float64); ok {
+			return f
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/golang/geo/s1"
+)
+
+func TestNearestPlaces(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"NAME":"Bigville","POP_MAX":100000},
+				"geometry":{"type":"Point","coordinates":[1,1]}},
+				{"type":"Feature",
+				"properties":{"name":"Smallville","pop_max":100},
+				"geometry":{"type":"Point","coordinates":[1,1.5]}},
+				{"type":"Feature",
+				"properties":{"NAME":"Faraway"},
+				"geometry":{"type":"Point","coordinates":[10,10]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		k        int
+		maxDist  s1.Angle
+		expected []Place
+		err      error
+	}{
+		{
+			name:    "nearest",
+			in:      []float64{1, 1.4},
+			k:       1,
+			maxDist: s1.Degree,
+			expected: []Place{{
+				Name:       "Smallville",
+				Population: 100,
+				Coord:      []float64{1, 1.5},
+				Location:   Location{CountryCode3: "TST"},
+			}},
+		},
+		{
+			name:    "all within distance",
+			in:      []float64{1, 1.4},
+			maxDist: s1.Degree,
+			expected: []Place{
+				{
+					Name:       "Smallville",
+					Population: 100,
+					Coord:      []float64{1, 1.5},
+					Location:   Location{CountryCode3: "TST"},
+				},
+				{
+					Name:       "Bigville",
+					Population: 100000,
+					Coord:      []float64{1, 1},
+					Location:   Location{CountryCode3: "TST"},
+				},
+			},
+		},
+		{
+			name:     "outside polygons",
+			in:       []float64{10, 10.1},
+			k:        5,
+			maxDist:  s1.Degree,
+			expected: []Place{{Name: "Faraway", Coord: []float64{10, 10}}},
+		},
+		{
+			name:    "too far",
+			in:      []float64{5, 5},
+			maxDist: s1.Degree,
+			err:     ErrLocationNotFound,
+		},
+	}
+
+	r, err := NewWithOptions(WithReaders(strings.NewReader(testgeo)), WithPlaces())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The places are kept in snapshots as well
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []*Rgeo{r, loaded} {
+				result, err := r.NearestPlaces(test.in, test.k, test.maxDist)
+				if !errors.Is(err, test.err) {
+					t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+				}
+
+				for i := range result {
+					if result[i].Distance <= 0 || result[i].Distance > test.maxDist {
+						t.Errorf("distance %v out of range", result[i].Distance)
+					}
+
+					result[i].Distance = 0
+				}
+
+				if diff := deep.Equal(test.expected, result); diff != nil {
+					t.Error(diff)
+				}
+			}
+		})
+	}
+}
+
+func TestNearestPlaces_NoPlaces(t *testing.T) {
+	// Countries110 doesn't have any points
+	r, err := New(Countries110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.NearestPlaces([]float64{-1.3, 51.8}, 1, s1.Degree); !errors.Is(err, ErrLocationNotFound) {
+		t.Errorf("expected error: %s\n got: %s\n", ErrLocationNotFound, err)
+	}
+
+	// The points aren't places without WithPlaces
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"NAME":"Bigville"},
+				"geometry":{"type":"Point","coordinates":[1,1]}}
+			]
+		}`
+
+	if _, err := NewFromReaders(strings.NewReader(testgeo)); err == nil {
+		t.Error("expected error for points without WithPlaces")
+	}
+}
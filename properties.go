@@ -0,0 +1,94 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+// properties holds the raw GeoJSON properties of a Location. It's kept behind
+// a pointer so that == still compiles for Location, but it compares the
+// pointers rather than the properties, see WithProperties.
+type properties struct {
+	m map[string]interface{}
+}
+
+// newProperties returns the given GeoJSON properties, or just those with the
+// given keys if there are any.
+func newProperties(p map[string]interface{}, keys []string) *properties {
+	if len(keys) == 0 {
+		return &properties{m: p}
+	}
+
+	m := make(map[string]interface{}, len(keys))
+
+	for _, k := range keys {
+		if v, ok := p[k]; ok {
+			m[k] = v
+		}
+	}
+
+	return &properties{m: m}
+}
+
+// merge returns the union of both sets of properties, where the values in p
+// take precedence over those in other.
+func (p *properties) merge(other *properties) *properties {
+	switch {
+	case other == nil:
+		return p
+	case p == nil:
+		return other
+	}
+
+	m := make(map[string]interface{}, len(p.m)+len(other.m))
+
+	for k, v := range other.m {
+		m[k] = v
+	}
+
+	for k, v := range p.m {
+		m[k] = v
+	}
+
+	return &properties{m: m}
+}
+
+// Property returns the raw GeoJSON property with the given key, from the
+// feature(s) that the Location came from. This only works when the Rgeo was
+// created using WithProperties. Where several features were matched, the value
+// comes from the first one that has it, the same as the other Location fields.
+func (l Location) Property(key string) (interface{}, bool) {
+	if l.props == nil {
+		return nil, false
+	}
+
+	v, ok := l.props.m[key]
+
+	return v, ok
+}
+
+// Properties returns a copy of all of the raw GeoJSON properties kept for the
+// Location, see Property.
+func (l Location) Properties() map[string]interface{} {
+	if l.props == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{}, len(l.props.m))
+
+	for k, v := range l.props.m {
+		m[k] = v
+	}
+
+	return m
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestWithProperties(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST","POP_EST":1000,"TYPE":"Country"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"name_conve":"Testville","POP_EST":10,"WIKIDATAID":"Q1"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0.5,0.5],[1,0.5],[1,1],[0.5,1],[0.5,0.5]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		keys     []string
+		in       []float64
+		expected map[string]interface{}
+	}{
+		{
+			name: "all",
+			in:   []float64{1.5, 1.5},
+			expected: map[string]interface{}{
+				"ISO_A3":  "TST",
+				"POP_EST": 1000.0,
+				"TYPE":    "Country",
+			},
+		},
+		{
+			name: "merged",
+			in:   []float64{0.75, 0.75},
+			expected: map[string]interface{}{
+				"ISO_A3":     "TST",
+				"POP_EST":    1000.0,
+				"TYPE":       "Country",
+				"name_conve": "Testville",
+				"WIKIDATAID": "Q1",
+			},
+		},
+		{
+			name: "filtered",
+			keys: []string{"POP_EST", "WIKIDATAID"},
+			in:   []float64{0.75, 0.75},
+			expected: map[string]interface{}{
+				"POP_EST":    1000.0,
+				"WIKIDATAID": "Q1",
+			},
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewWithOptions(
+				WithReaders(strings.NewReader(testgeo)),
+				WithProperties(test.keys...),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(test.expected, result.Properties()); diff != nil {
+				t.Error(diff)
+			}
+
+			v, ok := result.Property("POP_EST")
+			if !ok || v != 1000.0 {
+				t.Errorf("expected POP_EST: 1000\n got: %v\n", v)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		r, err := NewWithOptions(WithReaders(strings.NewReader(testgeo)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := r.ReverseGeocode([]float64{1.5, 1.5})
+		if err != nil {
+			t.Error(err)
+		}
+		if _, ok := result.Property("POP_EST"); ok {
+			t.Error("expected no properties without WithProperties")
+		}
+		if result != (Location{CountryCode3: "TST"}) {
+			t.Errorf("expected Location to stay comparable, got: %v", result)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"fmt"
+
+	"github.com/golang/geo/r1"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// regionDepth is how many levels below the coarsest cell covering a region
+// the region and the polygons are compared. Where both boundaries pass through
+// a cell this small they're treated as intersecting, and the area of the cell
+// is split according to its centre, so this sets the resolution of the
+// results relative to the size of the region.
+const regionDepth = 8
+
+// Intersection is a single feature that intersects a region, as returned by
+// Intersecting.
+type Intersection struct {
+	Match
+
+	// Fraction of the area of the region that is inside the feature, only
+	// set by IntersectingArea
+	Fraction float64 `json:"fraction,omitempty"`
+}
+
+// Intersecting returns every feature that intersects the given region, in the
+// same order as ReverseGeocodeAll. Like ReverseGeocodeAll the Locations aren't
+// merged, so e.g. a map viewport over the Channel will give both the United
+// Kingdom and France. ErrLocationNotFound is returned if none of them do.
+//
+// Any s2.Region can be used, see IntersectingBounds and IntersectingPolygon
+// for the more common cases. Where both the region's boundary and a
+// polygon's boundary pass through the same small cell (about 1/256th the size
+// of the region) they are counted as intersecting, so features that only
+// touch the region at its edges might be included.
+func (r *Rgeo) Intersecting(region s2.Region) ([]Intersection, error) {
+	return r.intersecting(region, false)
+}
+
+// IntersectingArea works like Intersecting, but also works out what fraction
+// of the region's area is inside each of the features, e.g. for how much of a
+// delivery zone is in each province. This is an approximation at the same
+// resolution as Intersecting, and is slower. Features that only touch the
+// region, so that none of its area is inside them, are left out.
+func (r *Rgeo) IntersectingArea(region s2.Region) ([]Intersection, error) {
+	return r.intersecting(region, true)
+}
+
+// IntersectingBounds returns every feature that intersects the given
+// longitude/latitude bounding box, see Intersecting. The box is bounded by
+// lines of longitude and latitude, as a map viewport would be, and if the
+// minimum longitude is greater than the maximum it wraps around the
+// antimeridian. For the area fractions use IntersectingArea with
+// RectFromBounds.
+func (r *Rgeo) IntersectingBounds(b *geom.Bounds) ([]Intersection, error) {
+	return r.Intersecting(RectFromBounds(b))
+}
+
+// IntersectingPolygon returns every feature that intersects the given
+// polygon, see Intersecting. For the area fractions use IntersectingArea with
+// PolygonFromGeom.
+func (r *Rgeo) IntersectingPolygon(p *geom.Polygon) ([]Intersection, error) {
+	region, err := PolygonFromGeom(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Intersecting(region)
+}
+
+// RectFromBounds converts a longitude/latitude bounding box to an s2.Rect, for
+// use with Intersecting and IntersectingArea.
+func RectFromBounds(b *geom.Bounds) s2.Rect {
+	return s2.Rect{
+		Lat: r1.Interval{
+			Lo: (s1.Angle(b.Min(1)) * s1.Degree).Radians(),
+			Hi: (s1.Angle(b.Max(1)) * s1.Degree).Radians(),
+		},
+		Lng: s1.IntervalFromEndpoints(
+			(s1.Angle(b.Min(0)) * s1.Degree).Radians(),
+			(s1.Angle(b.Max(0)) * s1.Degree).Radians(),
+		),
+	}
+}
+
+// PolygonFromGeom converts a polygon to an s2.Polygon, for use with
+// Intersecting and IntersectingArea. The rings are oriented the same way as
+// the datasets are by default, so the inside is the smaller of the two areas
+// each ring divides the globe into.
+func PolygonFromGeom(p *geom.Polygon) (*s2.Polygon, error) {
+	poly, err := polygonFromPolygon(p, &conversion{})
+	if err != nil {
+		return nil, fmt.Errorf("bad polygon: %w", err)
+	}
+
+	return poly, nil
+}
+
+// intersecting does the work for Intersecting and IntersectingArea.
+func (r *Rgeo) intersecting(region s2.Region, area bool) ([]Intersection, error) {
+	covering := s2.NewRegionCoverer().Covering(region)
+	if len(covering) == 0 {
+		return nil, ErrLocationNotFound
+	}
+
+	level := s2.MaxLevel
+	for _, id := range covering {
+		level = min(level, id.Level())
+	}
+
+	level = min(level+regionDepth, s2.MaxLevel)
+
+	var regionArea float64
+
+	if area {
+		for _, id := range covering {
+			regionArea += intersectionArea(region, nil, id, level)
+		}
+	}
+
+	bound := region.RectBound()
+
+	var res []Intersection
+
+	for id := 0; id < r.index.Len(); id++ {
+		shape := r.index.Shape(int32(id))
+
+		p, ok := shape.(*s2.Polygon)
+		if !ok || !bound.Intersects(p.RectBound()) {
+			continue
+		}
+
+		var (
+			found    bool
+			fraction float64
+		)
+
+		for _, cell := range covering {
+			if area {
+				fraction += intersectionArea(region, p, cell, level)
+			} else if intersects(region, p, cell, level) {
+				found = true
+				break
+			}
+		}
+
+		if area && fraction > 0 && regionArea > 0 {
+			found = true
+			fraction = min(fraction/regionArea, 1)
+		}
+
+		if found {
+			res = append(res, Intersection{Match: r.locs[shape], Fraction: fraction})
+		}
+	}
+
+	if len(res) == 0 {
+		return nil, ErrLocationNotFound
+	}
+
+	return res, nil
+}
+
+// intersects reports whether the region and the polygon intersect within the
+// given cell, subdividing it down to the given level where their boundaries
+// both cross it.
+func intersects(region s2.Region, p *s2.Polygon, id s2.CellID, level int) bool {
+	cell := s2.CellFromCellID(id)
+
+	if !region.IntersectsCell(cell) || !p.IntersectsCell(cell) {
+		return false
+	}
+
+	if region.ContainsCell(cell) || p.ContainsCell(cell) || id.Level() >= level {
+		return true
+	}
+
+	for _, child := range id.Children() {
+		if intersects(region, p, child, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// intersectionArea returns the area of the intersection of the region and the
+// polygon within the given cell, in steradians, or just the area of the region
+// if the polygon is nil. Cells at the given level which both boundaries cross
+// are counted by whether their centre is inside both.
+func intersectionArea(region s2.Region, p *s2.Polygon, id s2.CellID, level int) float64 {
+	cell := s2.CellFromCellID(id)
+
+	if !region.IntersectsCell(cell) || (p != nil && !p.IntersectsCell(cell)) {
+		return 0
+	}
+
+	if region.ContainsCell(cell) && (p == nil || p.ContainsCell(cell)) {
+		return cell.ExactArea()
+	}
+
+	if id.Level() >= level {
+		if c := cell.Center(); region.ContainsPoint(c) && (p == nil || p.ContainsPoint(c)) {
+			return cell.ExactArea()
+		}
+
+		return 0
+	}
+
+	var a float64
+	for _, child := range id.Children() {
+		a += intersectionArea(region, p, child, level)
+	}
+
+	return a
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/twpayne/go-geom"
+)
+
+func TestIntersecting(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TSU"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[2,0],[4,0],[4,2],[2,2],[2,0]]]}}
+			]
+		}`
+
+	tst := Match{Location: Location{CountryCode3: "TST"}, Dataset: 0, Feature: 0}
+	tsu := Match{Location: Location{CountryCode3: "TSU"}, Dataset: 0, Feature: 1}
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected []Intersection
+		err      error
+	}{
+		{
+			name:     "inside one",
+			in:       []float64{0.5, 0.5, 1.5, 1.5},
+			expected: []Intersection{{Match: tst, Fraction: 1}},
+		},
+		{
+			name:     "across both",
+			in:       []float64{1, 0.5, 3, 1.5},
+			expected: []Intersection{{Match: tst, Fraction: 0.5}, {Match: tsu, Fraction: 0.5}},
+		},
+		{
+			name:     "partly outside",
+			in:       []float64{3, 1, 5, 3},
+			expected: []Intersection{{Match: tsu, Fraction: 0.25}},
+		},
+		{
+			name: "outside",
+			in:   []float64{5, 5, 6, 6},
+			err:  ErrLocationNotFound,
+		},
+	}
+
+	r, err := NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			bounds := geom.NewBounds(geom.XY).Set(test.in...)
+
+			// Without the areas
+			var expected []Intersection
+			for _, e := range test.expected {
+				expected = append(expected, Intersection{Match: e.Match})
+			}
+
+			res, err := r.IntersectingBounds(bounds)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+
+			if diff := deep.Equal(expected, res); diff != nil {
+				t.Error(diff)
+			}
+
+			// The same box as a polygon
+			poly := geom.NewPolygonFlat(geom.XY, []float64{
+				test.in[0], test.in[1], test.in[2], test.in[1], test.in[2], test.in[3],
+				test.in[0], test.in[3], test.in[0], test.in[1],
+			}, []int{10})
+
+			res, err = r.IntersectingPolygon(poly)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+
+			if diff := deep.Equal(expected, res); diff != nil {
+				t.Error(diff)
+			}
+
+			// With the areas, which are only approximate
+			res, err = r.IntersectingArea(RectFromBounds(bounds))
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+
+			for i := range res {
+				if i < len(test.expected) && math.Abs(res[i].Fraction-test.expected[i].Fraction) < 0.02 {
+					res[i].Fraction = test.expected[i].Fraction
+				}
+			}
+
+			if diff := deep.Equal(test.expected, res); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIntersecting_Countries(t *testing.T) {
+	r, err := New(Countries110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected []string
+	}{
+		{
+			name:     "English Channel",
+			in:       []float64{-2, 49, 3, 52},
+			expected: []string{"France", "Belgium", "United Kingdom"},
+		},
+		{
+			name:     "Iberia",
+			in:       []float64{-12, 36, -6, 42},
+			expected: []string{"Portugal", "Spain"},
+		},
+		{
+			name:     "Across the antimeridian",
+			in:       []float64{170, -20, -170, 0},
+			expected: []string{"Fiji"},
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res, err := r.IntersectingBounds(geom.NewBounds(geom.XY).Set(test.in...))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var countries []string
+			for _, i := range res {
+				countries = append(countries, i.Location.Country)
+			}
+
+			if diff := deep.Equal(test.expected, countries); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
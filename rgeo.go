@@ -16,16 +16,13 @@ specific language governing permissions and limitations under the License.
 package rgeo
 
 import (
-	"bytes"
-	"compress/gzip"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"math"
+	"sync"
 
 	"github.com/golang/geo/s2"
 	"github.com/twpayne/go-geom"
-	"github.com/twpayne/go-geom/encoding/geojson"
 )
 
 // ErrLocationNotFound is returned when no country is found for given
@@ -53,13 +50,72 @@ type Location struct {
 	// ISO 3166-2 code
 	ProvinceCode string `json:"province_code,omitempty"`
 
+	// Second level administrative division, e.g. a county or district
+	District string `json:"district,omitempty"`
+
 	City string `json:"city,omitempty"`
+
+	// IANA time zone name, e.g. "Europe/London", from a time zone dataset
+	TimeZone string `json:"time_zone,omitempty"`
+
+	// Name of the ocean, sea, bay etc. from a marine dataset, only set
+	// when the coordinate isn't on land
+	WaterBody string `json:"water_body,omitempty"`
+
+	// ISO 3166-1 alpha-3 code of the country with jurisdiction over the
+	// waters, and the type of zone, e.g. "200NM" for an Exclusive Economic
+	// Zone or "12NM" for territorial waters. These come from the Marine
+	// Regions maritime boundaries, which aren't included, see the README.
+	MaritimeCountryCode3 string `json:"maritime_country_code_3,omitempty"`
+	MaritimeZoneType     string `json:"maritime_zone_type,omitempty"`
+
+	// Raw GeoJSON properties, only kept when using WithProperties
+	props *properties
+
+	// Names in other languages, only kept when using WithLanguages
+	names *localNames
+
+	// Polygon the Location came from, only kept when using WithGeometry
+	poly *polygon
+}
+
+// Match is a single feature containing a coordinate, as returned by
+// ReverseGeocodeAll.
+type Match struct {
+	// Location information of this feature alone
+	Location Location `json:"location"`
+
+	// Index of the dataset given to New that the feature came from
+	Dataset int `json:"dataset"`
+
+	// Index of the feature within its dataset
+	Feature int `json:"feature"`
 }
 
 // Rgeo is the type used to hold pre-created polygons for reverse geocoding.
 type Rgeo struct {
 	index *s2.ShapeIndex
-	locs  map[s2.Shape]Location
+	locs  map[s2.Shape]Match
+	opts  options
+
+	// Optional lookup table, see WithCellCache
+	cache     *cellCache
+	cacheOnce sync.Once
+
+	// Problems found in the datasets, see WithValidation
+	issues []ValidationIssue
+
+	// Only used while adding the datasets, see WithWorldview
+	pov *worldview
+
+	// Populated places, see NearestPlaces
+	places     []Place
+	placeIndex *s2.ShapeIndex
+	placesOnce sync.Once
+
+	// Features by their codes, see ByCountryCode
+	codes     codeIndex
+	codesOnce sync.Once
 }
 
 // Go generate commands to regenerate the included datasets, this assumes you
@@ -74,57 +130,10 @@ type Rgeo struct {
 // takes any number of datasets as an argument. The included datasets are:
 // Countries110, Countries10, Provinces10 and Cities10. Provinces10 includes all
 // of the country information so if that's all you want don't use Countries as
-// well. Cities10 only includes cities so you'll probably want to use
-// Provinces10 with it.
+// well. Cities10 only includes cities so you'll probably want to use one of the
+// others with them.
 func New(datasets ...func() []byte) (*Rgeo, error) {
-	// Parse GeoJSON
-	var fc geojson.FeatureCollection
-
-	for i, dataset := range datasets {
-		br := bytes.NewReader(dataset())
-		if br.Len() == 0 {
-			return nil, fmt.Errorf("no data in dataset %d", i)
-		}
-
-		zr, err := gzip.NewReader(br)
-		if err != nil {
-			return nil, fmt.Errorf("decompression failed for dataset %d: %w", i, err)
-		}
-
-		// Parse GeoJSON
-		var tfc geojson.FeatureCollection
-		if err := json.NewDecoder(zr).Decode(&tfc); err != nil {
-			return nil, fmt.Errorf("invalid JSON in dataset %d: %w", i, err)
-		}
-
-		if err := zr.Close(); err != nil {
-			return nil, fmt.Errorf("failed to close gzip reader for dataset %d: %w", i, err)
-		}
-
-		fc.Features = append(fc.Features, tfc.Features...)
-	}
-
-	// Initialise Rgeo struct
-	ret := new(Rgeo)
-	ret.index = s2.NewShapeIndex()
-	ret.locs = make(map[s2.Shape]Location)
-
-	// Convert GeoJSON features from geom (multi)polygons to s2 polygons
-	for _, c := range fc.Features {
-		p, err := polygonFromGeometry(c.Geometry)
-		if err != nil {
-			return nil, fmt.Errorf("bad polygon in geometry: %w", err)
-		}
-
-		ret.index.Add(p)
-
-		// The s2 ContainsPointQuery returns the shapes that contain the given
-		// point, but I haven't found any way to attach the location information
-		// to the shapes, so I use a map to get the information.
-		ret.locs[p] = getLocationStrings(c.Properties)
-	}
-
-	return ret, nil
+	return NewWithOptions(WithDatasets(datasets...))
 }
 
 // Build builds the underlying shape index. This ensures that future calls to
@@ -132,6 +141,20 @@ func New(datasets ...func() []byte) (*Rgeo, error) {
 // will build the index implicitly and experience a 1s+ delay.
 func (r *Rgeo) Build() {
 	r.index.Build()
+	r.buildCache()
+	r.buildPlaces()
+}
+
+// buildCache builds the cell cache if one was asked for, the first time it's
+// called.
+func (r *Rgeo) buildCache() {
+	if r.opts.cellLevel == 0 {
+		return
+	}
+
+	r.cacheOnce.Do(func() {
+		r.cache = r.buildCellCache(r.opts.cellLevel)
+	})
 }
 
 // ReverseGeocode returns the country in which the given coordinate is located.
@@ -141,6 +164,20 @@ func (r *Rgeo) Build() {
 // (i.e. []float64{lon, lat}).
 func (r *Rgeo) ReverseGeocode(loc geom.Coord) (Location, error) {
 	query := s2.NewContainsPointQuery(r.index, s2.VertexModelOpen)
+	return r.reverseGeocode(query, loc)
+}
+
+// reverseGeocode does the work for ReverseGeocode using the given query, so
+// that it can be reused across lookups by a single goroutine.
+func (r *Rgeo) reverseGeocode(query *s2.ContainsPointQuery, loc geom.Coord) (Location, error) {
+	if r.opts.cellLevel != 0 {
+		r.buildCache()
+
+		if l, ok := r.cache.lookup(cellIDFromCoord(loc)); ok {
+			return l, nil
+		}
+	}
+
 	res := query.ContainingShapes(pointFromCoord(loc))
 	if len(res) == 0 {
 		return Location{}, ErrLocationNotFound
@@ -149,10 +186,46 @@ func (r *Rgeo) ReverseGeocode(loc geom.Coord) (Location, error) {
 	return r.combineLocations(res), nil
 }
 
+// ReverseGeocodeAll returns every feature that contains the given coordinate,
+// in the order that they were given to New, except for those moved to another
+// country by WithWorldview, which come first. Unlike ReverseGeocode the
+// Locations aren't merged, so where features overlap (e.g. when using
+// Provinces10 and Cities10 together, or with disputed areas) you can decide
+// for yourself which one takes precedence.
+func (r *Rgeo) ReverseGeocodeAll(loc geom.Coord) ([]Match, error) {
+	query := s2.NewContainsPointQuery(r.index, s2.VertexModelOpen)
+	res := query.ContainingShapes(pointFromCoord(loc))
+	if len(res) == 0 {
+		return nil, ErrLocationNotFound
+	}
+
+	matches := make([]Match, len(res))
+	for i, shape := range res {
+		matches[i] = r.locs[shape]
+	}
+
+	return matches, nil
+}
+
 // combineLocations combines the Locations for the given s2 Shapes.
 func (r *Rgeo) combineLocations(s []s2.Shape) (l Location) {
+	// The marine polygons overlap the coastlines, so land wins and the water
+	// bodies are only used when none of the shapes are on land
+	var land bool
+
 	for _, shape := range s {
-		loc := r.locs[shape]
+		if r.locs[shape].Location.onLand() {
+			land = true
+			break
+		}
+	}
+
+	for _, shape := range s {
+		loc := r.locs[shape].Location
+		if land && loc.WaterBody != "" && !loc.onLand() {
+			continue
+		}
+
 		l = Location{
 			Country:      firstNonEmpty(l.Country, loc.Country),
 			CountryLong:  firstNonEmpty(l.CountryLong, loc.CountryLong),
@@ -163,13 +236,32 @@ func (r *Rgeo) combineLocations(s []s2.Shape) (l Location) {
 			SubRegion:    firstNonEmpty(l.SubRegion, loc.SubRegion),
 			Province:     firstNonEmpty(l.Province, loc.Province),
 			ProvinceCode: firstNonEmpty(l.ProvinceCode, loc.ProvinceCode),
+			District:     firstNonEmpty(l.District, loc.District),
 			City:         firstNonEmpty(l.City, loc.City),
+			TimeZone:     firstNonEmpty(l.TimeZone, loc.TimeZone),
+			WaterBody:    firstNonEmpty(l.WaterBody, loc.WaterBody),
+
+			// Kept apart from the country fields, so that points near the
+			// coast can be in both a country and its (or another's) waters
+			MaritimeCountryCode3: firstNonEmpty(l.MaritimeCountryCode3, loc.MaritimeCountryCode3),
+			MaritimeZoneType:     firstNonEmpty(l.MaritimeZoneType, loc.MaritimeZoneType),
+
+			props: l.props.merge(loc.props),
+			names: l.names.merge(loc.names),
+			poly:  l.poly.smaller(loc.poly),
 		}
 	}
 
 	return
 }
 
+// onLand reports whether l has any information about places on land, i.e.
+// anything other than the time zone, water body or maritime zone.
+func (l Location) onLand() bool {
+	return firstNonEmpty(l.Country, l.CountryLong, l.CountryCode2, l.CountryCode3,
+		l.Province, l.ProvinceCode, l.District, l.City) != ""
+}
+
 // firstNonEmpty returns the first non empty parameter.
 func firstNonEmpty(s ...string) string {
 	for _, i := range s {
@@ -181,22 +273,6 @@ func firstNonEmpty(s ...string) string {
 	return ""
 }
 
-// Get the relevant strings from the GeoJSON properties.
-func getLocationStrings(p map[string]interface{}) Location {
-	return Location{
-		Country:      getPropertyString(p, "ADMIN", "admin"),
-		CountryLong:  getPropertyString(p, "FORMAL_EN"),
-		CountryCode2: getPropertyString(p, "ISO_A2"),
-		CountryCode3: getPropertyString(p, "ISO_A3"),
-		Continent:    getPropertyString(p, "CONTINENT"),
-		Region:       getPropertyString(p, "REGION_UN"),
-		SubRegion:    getPropertyString(p, "SUBREGION"),
-		Province:     getPropertyString(p, "name"),
-		ProvinceCode: getPropertyString(p, "iso_3166_2"),
-		City:         strings.TrimSuffix(getPropertyString(p, "name_conve"), "2"),
-	}
-}
-
 // getPropertyString gets the value from a map given the key as a string, or
 // from the next given key if the previous fails.
 func getPropertyString(m map[string]interface{}, keys ...string) (s string) {
@@ -211,8 +287,59 @@ func getPropertyString(m map[string]interface{}, keys ...string) (s string) {
 	return
 }
 
+// getPropertyCode is getPropertyString for codes, but skips the "-99" that
+// Natural Earth uses when a feature doesn't have a code, so that the next key
+// can fill it in. It's still "-99" if that's all there is.
+func getPropertyCode(m map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "-99" {
+			return s
+		}
+	}
+
+	return getPropertyString(m, keys...)
+}
+
+// conversion holds the settings for converting geom polygons to s2 polygons,
+// and collects any repairs made along the way.
+type conversion struct {
+	// Whether to trust the winding order of the rings, otherwise each ring is
+	// assumed to enclose less than half of the sphere
+	oriented bool
+
+	// Whether to fix problems with the rings instead of failing
+	repair  bool
+	repairs []string
+
+	// Whether to check that the polygons of a MultiPolygon aren't inside one
+	// another, which needs to know which ring each loop came from
+	validate bool
+	rings    map[*s2.Loop]ring
+	parts    int
+}
+
+// ring is where a loop came from in the GeoJSON.
+type ring struct {
+	// Index of the polygon in a MultiPolygon, or 0 for a Polygon
+	part int
+
+	// Whether it was the exterior ring of the polygon, rather than a hole
+	shell bool
+}
+
+// repaired records that a problem was fixed, ignoring repeats.
+func (c *conversion) repaired(reason string) {
+	for _, r := range c.repairs {
+		if r == reason {
+			return
+		}
+	}
+
+	c.repairs = append(c.repairs, reason)
+}
+
 // polygonFromGeometry converts a geom.T to an s2 Polygon.
-func polygonFromGeometry(g geom.T) (*s2.Polygon, error) {
+func polygonFromGeometry(g geom.T, c *conversion) (*s2.Polygon, error) {
 	var (
 		polygon *s2.Polygon
 		err     error
@@ -220,9 +347,9 @@ func polygonFromGeometry(g geom.T) (*s2.Polygon, error) {
 
 	switch t := g.(type) {
 	case *geom.Polygon:
-		polygon, err = polygonFromPolygon(t)
+		polygon, err = polygonFromPolygon(t, c)
 	case *geom.MultiPolygon:
-		polygon, err = polygonFromMultiPolygon(t)
+		polygon, err = polygonFromMultiPolygon(t, c)
 	default:
 		return nil, errors.New("needs Polygon or MultiPolygon")
 	}
@@ -235,11 +362,11 @@ func polygonFromGeometry(g geom.T) (*s2.Polygon, error) {
 }
 
 // Converts a geom MultiPolygon to an s2 Polygon.
-func polygonFromMultiPolygon(p *geom.MultiPolygon) (*s2.Polygon, error) {
+func polygonFromMultiPolygon(p *geom.MultiPolygon, c *conversion) (*s2.Polygon, error) {
 	loops := make([]*s2.Loop, 0, p.NumPolygons())
 
 	for i := 0; i < p.NumPolygons(); i++ {
-		this, err := loopSliceFromPolygon(p.Polygon(i))
+		this, err := loopSliceFromPolygon(p.Polygon(i), c)
 		if err != nil {
 			return nil, err
 		}
@@ -247,97 +374,216 @@ func polygonFromMultiPolygon(p *geom.MultiPolygon) (*s2.Polygon, error) {
 		loops = append(loops, this...)
 	}
 
-	return s2.PolygonFromLoops(loops), nil
+	return polygonFromLoops(loops, c)
 }
 
 // Converts a geom Polygon to an s2 Polygon.
-func polygonFromPolygon(p *geom.Polygon) (*s2.Polygon, error) {
-	loops, err := loopSliceFromPolygon(p)
-	return s2.PolygonFromLoops(loops), err
+func polygonFromPolygon(p *geom.Polygon, c *conversion) (*s2.Polygon, error) {
+	loops, err := loopSliceFromPolygon(p, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return polygonFromLoops(loops, c)
+}
+
+// polygonFromLoops builds an s2 Polygon from loops made by
+// loopSliceFromPolygon.
+func polygonFromLoops(loops []*s2.Loop, c *conversion) (*s2.Polygon, error) {
+	// Repairing can drop every ring, which would leave an empty polygon that
+	// doesn't contain anything
+	if len(loops) == 0 {
+		return nil, errors.New("no valid rings")
+	}
+
+	// Oriented loops have holes going clockwise, whereas normalised loops
+	// all enclose the smaller area and s2 works out which ones are holes.
+	var p *s2.Polygon
+	if c.oriented {
+		p = s2.PolygonFromOrientedLoops(loops)
+	} else {
+		p = s2.PolygonFromLoops(loops)
+	}
+
+	if c.validate {
+		return c.checkShells(p)
+	}
+
+	return p, nil
 }
 
 // Converts a geom Polygon to slice of s2 Loop.
 //
 // Modified from types.loopFromPolygon from github.com/dgraph-io/dgraph.
-func loopSliceFromPolygon(p *geom.Polygon) ([]*s2.Loop, error) {
+func loopSliceFromPolygon(p *geom.Polygon, c *conversion) ([]*s2.Loop, error) {
 	loops := make([]*s2.Loop, 0, p.NumLinearRings())
 
+	part := c.parts
+	c.parts++
+
 	for i := 0; i < p.NumLinearRings(); i++ {
 		r := p.LinearRing(i)
 		n := r.NumCoords()
 
 		if n < 4 {
+			if c.repair {
+				c.repaired("dropped ring with less than 4 points")
+				continue
+			}
+
 			return nil, errors.New("can't convert ring with less than 4 points")
 		}
 
 		if !r.Coord(0).Equal(geom.XY, r.Coord(n-1)) {
-			return nil, fmt.Errorf(
-				"last coordinate not same as first for polygon: %+v", p.FlatCoords())
+			if !c.repair {
+				return nil, fmt.Errorf(
+					"last coordinate not same as first for polygon: %+v", p.FlatCoords())
+			}
+
+			// pointsFromRing skips the last point, so close the ring by
+			// repeating the first one
+			c.repaired("closed unclosed ring")
+
+			flat := r.FlatCoords()
+			r = geom.NewLinearRingFlat(r.Layout(),
+				append(flat[:len(flat):len(flat)], flat[:r.Stride()]...))
 		}
 
-		// S2 specifies that the orientation of the polygons should be CCW.
-		// However there is no restriction on the orientation in WKB (or
-		// GeoJSON). To get the correct orientation we assume that the polygons
-		// are always less than one hemisphere. If they are bigger, we flip the
-		// orientation.
-		reverse := isClockwise(r)
-		l := loopFromRing(r, reverse)
-
-		// Since our clockwise check was approximate, we check the cap and
-		// reverse if needed.
-		if l.CapBound().Radius().Degrees() > 90 {
-			// Remaking the loop sometimes caused problems, this works better
-			l.Invert()
+		rings := [][]s2.Point{pointsFromRing(r)}
+		if c.repair {
+			rings = c.splitRing(rings[0])
 		}
 
-		loops = append(loops, l)
+		for _, pts := range rings {
+			if len(pts) < 3 && c.repair {
+				c.repaired("dropped degenerate ring")
+				continue
+			}
+
+			l := s2.LoopFromPoints(rotateToCorner(pts))
+
+			// S2 specifies that the orientation of the loops should be CCW,
+			// but there is no restriction on the orientation in GeoJSON
+			// before RFC 7946 (or in WKB). Unless we trust the orientation,
+			// we assume that every ring encloses less than half of the
+			// sphere, and check that using the spherical area of the loop, so
+			// it works for rings that contain a pole or cross the
+			// antimeridian. Loop.Normalize would do the same using the
+			// turning angle, but that goes wrong for rings that double back
+			// on themselves, which some of the data does.
+			if !c.oriented && l.Area() > 2*math.Pi {
+				l.Invert()
+			}
+
+			if c.validate {
+				if c.rings == nil {
+					c.rings = make(map[*s2.Loop]ring)
+				}
+
+				c.rings[l] = ring{part: part, shell: i == 0}
+			}
+
+			loops = append(loops, l)
+		}
 	}
 
 	return loops, nil
 }
 
-// Checks if a ring is clockwise or counter-clockwise. Note: This uses the
-// algorithm for planar polygons and doesn't work for spherical polygons that
-// contain the poles or the antimeridan discontinuity. We use this as a fast
-// approximation instead.
+// pointsFromRing converts a geom LinearRing to the vertices of an s2 Loop,
+// keeping the orientation.
 //
-// From github.com/dgraph-io/dgraph
-func isClockwise(r *geom.LinearRing) bool {
-	// The algorithm is described here
-	// https://en.wikipedia.org/wiki/Shoelace_formula
-	var a float64
-
+// Modified from github.com/dgraph-io/dgraph
+func pointsFromRing(r *geom.LinearRing) []s2.Point {
+	// In WKB, the last coordinate is repeated for a ring to form a closed loop.
+	// For s2 the points aren't allowed to repeat and the loop is assumed to be
+	// closed, so we skip the last point.
 	n := r.NumCoords()
+	pts := make([]s2.Point, 0, n-1)
 
-	for i := 0; i < n; i++ {
-		p1 := r.Coord(i)
-		p2 := r.Coord((i + 1) % n)
-		a += (p2.X() - p1.X()) * (p1.Y() + p2.Y())
+	for i := 0; i < n-1; i++ {
+		pt := pointFromCoord(r.Coord(i))
+
+		// Consecutive coordinates can end up as the same point, e.g. along
+		// the antimeridian or at the poles, which gives s2 a degenerate edge
+		if len(pts) > 0 && pts[len(pts)-1] == pt {
+			continue
+		}
+
+		pts = append(pts, pt)
 	}
 
-	return a > 0
+	for len(pts) > 1 && pts[len(pts)-1] == pts[0] {
+		pts = pts[:len(pts)-1]
+	}
+
+	return pts
 }
 
-// From github.com/dgraph-io/dgraph
-func loopFromRing(r *geom.LinearRing, reverse bool) *s2.Loop {
-	// In WKB, the last coordinate is repeated for a ring to form a closed loop.
-	// For s2 the points aren't allowed to repeat and the loop is assumed to be
-	// closed, so we skip the last point.
-	n := r.NumCoords()
-	pts := make([]s2.Point, n-1)
+// splitRing splits a ring that visits the same vertex more than once into
+// separate rings that don't, since s2 doesn't allow duplicate vertices. A
+// spike that goes out and back along the same edge ends up as a ring of two
+// points, which loopSliceFromPolygon drops.
+func (c *conversion) splitRing(pts []s2.Point) [][]s2.Point {
+	var (
+		rings [][]s2.Point
+		stack = make([]s2.Point, 0, len(pts))
+		seen  = make(map[s2.Point]int, len(pts))
+	)
 
-	for i := 0; i < n-1; i++ {
-		var c geom.Coord
-		if reverse {
-			c = r.Coord(n - 1 - i)
-		} else {
-			c = r.Coord(i)
+	for _, pt := range pts {
+		k, ok := seen[pt]
+		if !ok {
+			seen[pt] = len(stack)
+			stack = append(stack, pt)
+
+			continue
+		}
+
+		// Everything since the last visit to this point forms its own ring
+		c.repaired("removed duplicate vertices")
+		rings = append(rings, append([]s2.Point(nil), stack[k:]...))
+
+		for _, p := range stack[k+1:] {
+			delete(seen, p)
 		}
 
-		pts[i] = pointFromCoord(c)
+		stack = stack[:k+1]
 	}
 
-	return s2.LoopFromPoints(pts)
+	return append(rings, stack)
+}
+
+// rotateToCorner rotates the points of a loop so that the second one is the
+// most clearly defined corner. s2.LoopFromPoints works out which side of the
+// loop is the inside by looking at the second vertex, which goes wrong when
+// that vertex is nearly degenerate, such as the tip of a tiny spike.
+func rotateToCorner(pts []s2.Point) []s2.Point {
+	n := len(pts)
+	if n < 3 {
+		return pts
+	}
+
+	best, bestSize := 1, -1.0
+
+	for i := 0; i < n; i++ {
+		prev, this, next := pts[(i+n-1)%n], pts[i], pts[(i+1)%n]
+
+		// Twice the area of the triangle formed with the neighbouring points
+		size := prev.Sub(this.Vector).Cross(next.Sub(this.Vector)).Norm()
+		if size > bestSize {
+			best, bestSize = i, size
+		}
+	}
+
+	start := (best + n - 1) % n
+
+	return append(pts[start:n:n], pts[:start]...)
+}
+
+// cellIDFromCoord returns the leaf cell containing the given coordinate.
+func cellIDFromCoord(r geom.Coord) s2.CellID {
+	return s2.CellIDFromLatLng(s2.LatLngFromDegrees(r.Y(), r.X()))
 }
 
 // From github.com/dgraph-io/dgraph
@@ -352,7 +598,8 @@ func pointFromCoord(r geom.Coord) s2.Point {
 func (l Location) String() string {
 	ret := "<Location>"
 
-	// Special case for empty location
+	// Special case for empty location, ignoring any raw properties
+	l.props, l.names, l.poly = nil, nil, nil
 	if l == (Location{}) {
 		return ret + " Empty Location"
 	}
@@ -362,6 +609,11 @@ func (l Location) String() string {
 		ret += " " + l.City + ","
 	}
 
+	// Add district name
+	if l.District != "" {
+		ret += " " + l.District + ","
+	}
+
 	// Add province name
 	if l.Province != "" {
 		ret += " " + l.Province + ","
@@ -372,6 +624,8 @@ func (l Location) String() string {
 		ret += " " + l.Country
 	} else if l.CountryLong != "" {
 		ret += " " + l.CountryLong
+	} else if l.WaterBody != "" {
+		ret += " " + l.WaterBody
 	}
 
 	// Add country code in brackets
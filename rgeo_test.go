@@ -20,6 +20,7 @@ import (
 	"compress/gzip"
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
@@ -259,6 +260,86 @@ func TestReverseGeocode(t *testing.T) {
 	}
 }
 
+func TestReverseGeocodeAll(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TSU"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1,1],[3,1],[3,3],[1,3],[1,1]]]}}
+			]
+		}`
+
+	testcity := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"name_conve":"Testville"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[1.2,1.2],[1.8,1.2],[1.8,1.8],[1.2,1.8],[1.2,1.2]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		err      error
+		expected []Match
+	}{
+		{
+			name: "one",
+			in:   []float64{0.5, 0.5},
+			err:  nil,
+			expected: []Match{
+				{Location: Location{CountryCode3: "TST"}, Dataset: 0, Feature: 0},
+			},
+		},
+		{
+			name: "overlap",
+			in:   []float64{1.5, 1.5},
+			err:  nil,
+			expected: []Match{
+				{Location: Location{CountryCode3: "TST"}, Dataset: 0, Feature: 0},
+				{Location: Location{CountryCode3: "TSU"}, Dataset: 0, Feature: 1},
+				{Location: Location{City: "Testville"}, Dataset: 1, Feature: 0},
+			},
+		},
+		{
+			name:     "out",
+			in:       []float64{5, 5},
+			err:      ErrLocationNotFound,
+			expected: nil,
+		},
+	}
+
+	r, err := New(
+		func() []byte { return compressData(t, testgeo) },
+		func() []byte { return compressData(t, testcity) },
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			result, err := r.ReverseGeocodeAll(test.in)
+			if err != test.err {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+			if diff := deep.Equal(test.expected, result); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
 func TestReverseGeocode_Countries(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test (countries) for short mode")
@@ -350,6 +431,148 @@ func TestReverseGeocode_Cities(t *testing.T) {
 	}
 }
 
+func TestReverseGeocode_Orientation(t *testing.T) {
+	testdata := []struct {
+		name string
+		in   string
+		// Points that should be inside the polygon, all others shouldn't be
+		inside [][]float64
+	}{
+		{
+			name:   "antimeridian",
+			in:     `[[[178,-18],[-178,-18],[-178,-16],[178,-16],[178,-18]]]`,
+			inside: [][]float64{{179, -17}, {-179, -17}},
+		},
+		{
+			name:   "antimeridian clockwise",
+			in:     `[[[178,-18],[178,-16],[-178,-16],[-178,-18],[178,-18]]]`,
+			inside: [][]float64{{179, -17}, {-179, -17}},
+		},
+		{
+			name:   "south pole",
+			in:     `[[[0,-80],[-90,-80],[180,-80],[90,-80],[0,-80]]]`,
+			inside: [][]float64{{0, -85}, {45, -89.99}},
+		},
+		{
+			name:   "south pole clockwise",
+			in:     `[[[0,-80],[90,-80],[180,-80],[-90,-80],[0,-80]]]`,
+			inside: [][]float64{{0, -85}, {45, -89.99}},
+		},
+		{
+			name: "south pole with vertices at the pole",
+			in: `[[[-180,-80],[-90,-80],[0,-80],[90,-80],[180,-80],
+					[180,-90],[-180,-90],[-180,-80]]]`,
+			inside: [][]float64{{0, -85}, {45, -89.99}},
+		},
+		{
+			name: "band around the equator",
+			in: `[[[-175,-5],[-90,-5],[0,-5],[90,-5],[175,-5],
+					[175,5],[90,5],[0,5],[-90,5],[-175,5],[-175,-5]]]`,
+			inside: [][]float64{{0, 0}, {170, 0}, {-170, 0}, {0.5, 0.5}},
+		},
+		{
+			name: "band around the equator clockwise",
+			in: `[[[-175,-5],[-175,5],[-90,5],[0,5],[90,5],[175,5],
+					[175,-5],[90,-5],[0,-5],[-90,-5],[-175,-5]]]`,
+			inside: [][]float64{{0, 0}, {170, 0}, {-170, 0}, {0.5, 0.5}},
+		},
+		{
+			name: "spike",
+			in: `[[[0,0],[1,0],[1,0.5],[1.5,0.5],[1.000001,0.500001],
+					[1,1],[0,1],[0,0]]]`,
+			inside: [][]float64{{0.5, 0.5}},
+		},
+	}
+
+	points := [][]float64{
+		{179, -17}, {-179, -17}, {0, -85}, {45, -89.99}, {0, 0}, {170, 0},
+		{-170, 0}, {0.5, 0.5}, {0, 45}, {180, 45}, {0, -45}, {-135, 90},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewFromReaders(strings.NewReader(
+				`{"type":"FeatureCollection","features":[{"type":"Feature",
+					"properties":{"ISO_A3":"TST"},
+					"geometry":{"type":"Polygon","coordinates":` + test.in + `}}]}`,
+			))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, p := range points {
+				expected := false
+
+				for _, in := range test.inside {
+					if deep.Equal(p, in) == nil {
+						expected = true
+					}
+				}
+
+				_, err := r.ReverseGeocode(p)
+				if (err == nil) != expected {
+					t.Errorf("%v: expected inside: %t\n", p, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestReverseGeocode_RFC7946Winding(t *testing.T) {
+	// A ring around the south pole going east is counterclockwise, so with
+	// the RFC 7946 winding order it covers everything north of it. Without it
+	// both rings are taken to be the smaller area they enclose.
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,-80],[90,-80],[180,-80],[-90,-80],[0,-80]],
+						[[0,0],[0,1],[1,1],[1,0],[0,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		oriented error
+		normal   error
+	}{
+		{name: "north", in: []float64{0, 45}, oriented: nil, normal: ErrLocationNotFound},
+		{name: "hole", in: []float64{0.5, 0.5}, oriented: ErrLocationNotFound, normal: nil},
+		{name: "pole", in: []float64{0, -85}, oriented: ErrLocationNotFound, normal: nil},
+	}
+
+	oriented, err := NewWithOptions(
+		WithReaders(strings.NewReader(testgeo)),
+		WithRFC7946Winding(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	normal, err := NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := oriented.ReverseGeocode(test.in); err != test.oriented {
+				t.Errorf("oriented: expected error: %s\n got: %s\n", test.oriented, err)
+			}
+			if _, err := normal.ReverseGeocode(test.in); err != test.normal {
+				t.Errorf("normal: expected error: %s\n got: %s\n", test.normal, err)
+			}
+		})
+	}
+}
+
 func TestNew_BadData(t *testing.T) {
 	testdata := []struct {
 		name string
@@ -370,7 +593,7 @@ func TestNew_BadData(t *testing.T) {
 								{"type":"Point","coordinates":[0,0]}}]}`,
 				)
 			},
-			err: "bad polygon in geometry: needs Polygon or MultiPolygon",
+			err: "bad polygon in geometry of feature 0 in dataset 0: needs Polygon or MultiPolygon",
 		},
 		{
 			name: "Small polygon",
@@ -382,7 +605,7 @@ func TestNew_BadData(t *testing.T) {
 								"coordinates":[[[1,2],[3,4],[1,2]]]}}]}`,
 				)
 			},
-			err: "bad polygon in geometry: can't convert ring with less than 4 points",
+			err: "bad polygon in geometry of feature 0 in dataset 0: can't convert ring with less than 4 points",
 		},
 		{
 			name: "No repeated end",
@@ -394,7 +617,7 @@ func TestNew_BadData(t *testing.T) {
 								"coordinates":[[[1,2],[3,4],[5,6],[7,8]]]}}]}`,
 				)
 			},
-			err: "bad polygon in geometry: " +
+			err: "bad polygon in geometry of feature 0 in dataset 0: " +
 				"last coordinate not same as first for polygon: [1 2 3 4 5 6 7 8]",
 		},
 		{
@@ -407,7 +630,7 @@ func TestNew_BadData(t *testing.T) {
 								"coordinates":[[[[1,2],[3,4],[5,6],[7,8]]]]}}]}`,
 				)
 			},
-			err: "bad polygon in geometry: " +
+			err: "bad polygon in geometry of feature 0 in dataset 0: " +
 				"last coordinate not same as first for polygon: [1 2 3 4 5 6 7 8]",
 		},
 		{
@@ -474,6 +697,14 @@ func TestString(t *testing.T) {
 			},
 			expected: "<Location> London, United Kingdom (GBR), Europe",
 		},
+		{
+			name: "No continent",
+			in: Location{
+				Country:      "Testland",
+				CountryCode3: "TST",
+			},
+			expected: "<Location> Testland (TST),",
+		},
 		{
 			name:     "Empty",
 			in:       Location{},
@@ -567,6 +798,23 @@ func BenchmarkReverseGeocode_10(b *testing.B) {
 	}
 }
 
+func BenchmarkReverseGeocode_10_CellCache(b *testing.B) {
+	r, err := NewWithOptions(WithDatasets(Countries10), WithCellCache(10))
+	if err != nil {
+		b.Error(err)
+	}
+
+	r.Build()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = r.ReverseGeocode([]float64{
+			(rand.Float64() * 360) - 180,
+			(rand.Float64() * 180) - 90,
+		})
+	}
+}
+
 func BenchmarkReverseGeocode_Prov10(b *testing.B) {
 	r, err := New(Provinces10)
 	if err != nil {
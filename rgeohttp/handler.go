@@ -0,0 +1,248 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+/*
+Package rgeohttp serves reverse geocoding over HTTP, for services that aren't
+written in Go. Handler answers the following requests, with the Locations in
+the same JSON as rgeo.Location:
+
+	GET  /reverse?lat=51.5&lon=-0.12
+	POST /reverse    [{"lat": 51.5, "lon": -0.12}, ...]
+	GET  /healthz
+	GET  /readyz
+
+A GET to /reverse returns a single Location, or a 404 if the coordinate isn't
+in any of the datasets. A POST takes an array of coordinates and returns an
+array of the same length, with either a "location" or an "error" for each.
+Errors are returned as {"error": "..."}.
+
+/healthz always reports that the server is up, whereas /readyz only does once
+the index has been built, so that load balancers can hold off sending lookups
+to it until they'll be fast.
+
+With WithNominatim single lookups are answered in the same format as
+Nominatim's reverse API instead, e.g.
+
+	GET /reverse?format=json&lat=43.07&lon=141.35
+
+	{"licence": "...", "lat": "43.07", "lon": "141.35",
+	 "display_name": "Sapporo, Hokkaidō, Japan",
+	 "address": {"city": "Sapporo", "state": "Hokkaidō",
+	  "ISO3166-2-lvl4": "JP-01", "country": "Japan", "country_code": "jp"}}
+*/
+package rgeohttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/sams96/rgeo"
+	"github.com/twpayne/go-geom"
+)
+
+// maxBatchBytes is the largest request body accepted for a batch.
+const maxBatchBytes = 8 << 20
+
+// Handler is an http.Handler that reverse geocodes with an *rgeo.Rgeo.
+type Handler struct {
+	r     *rgeo.Rgeo
+	mux   *http.ServeMux
+	ready atomic.Bool
+
+	// Answer single lookups like Nominatim, see WithNominatim
+	nominatim bool
+}
+
+// Option is an option for NewHandler.
+type Option func(*Handler)
+
+// Coord is a coordinate in a batch request.
+type Coord struct {
+	Lat *float64 `json:"lat"`
+	Lon *float64 `json:"lon"`
+}
+
+// Result is the answer for a single coordinate of a batch request.
+type Result struct {
+	Location *rgeo.Location `json:"location,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// NewHandler returns a Handler for r, which calls r.Build in the background
+// and reports ready once it has finished. Lookups made before then still work,
+// but wait for the index to be built.
+func NewHandler(r *rgeo.Rgeo, opts ...Option) *Handler {
+	h := &Handler{r: r, mux: http.NewServeMux()}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	if h.nominatim {
+		h.mux.HandleFunc("GET /reverse", h.reverseNominatim)
+		h.mux.HandleFunc("GET /reverse.php", h.reverseNominatim)
+	} else {
+		h.mux.HandleFunc("GET /reverse", h.reverse)
+	}
+
+	h.mux.HandleFunc("POST /reverse", h.reverseBatch)
+	h.mux.HandleFunc("GET /healthz", h.healthz)
+	h.mux.HandleFunc("GET /readyz", h.readyz)
+
+	go func() {
+		r.Build()
+		h.ready.Store(true)
+	}()
+
+	return h
+}
+
+// Ready reports whether the index has been built.
+func (h *Handler) Ready() bool {
+	return h.ready.Load()
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.mux.ServeHTTP(w, req)
+}
+
+// reverse answers a GET to /reverse.
+func (h *Handler) reverse(w http.ResponseWriter, req *http.Request) {
+	coord, err := parseCoord(req.URL.Query().Get("lat"), req.URL.Query().Get("lon"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	loc, err := h.r.ReverseGeocode(coord)
+	if errors.Is(err, rgeo.ErrLocationNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, loc)
+}
+
+// reverseBatch answers a POST to /reverse.
+func (h *Handler) reverseBatch(w http.ResponseWriter, req *http.Request) {
+	var in []Coord
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBatchBytes))
+	if err := dec.Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad request body: %w", err))
+		return
+	}
+
+	res := make([]Result, len(in))
+
+	// Only the valid coordinates are geocoded, idx maps them back to in
+	coords := make([]geom.Coord, 0, len(in))
+	idx := make([]int, 0, len(in))
+
+	for i, c := range in {
+		if c.Lat == nil || c.Lon == nil {
+			res[i].Error = "missing lat or lon"
+			continue
+		}
+
+		coord, err := checkCoord(*c.Lat, *c.Lon)
+		if err != nil {
+			res[i].Error = err.Error()
+			continue
+		}
+
+		coords = append(coords, coord)
+		idx = append(idx, i)
+	}
+
+	locs, errs := h.r.ReverseGeocodeBatch(coords, rgeo.BatchOptions{Context: req.Context()})
+
+	for j, i := range idx {
+		if errs[j] != nil {
+			res[i].Error = errs[j].Error()
+			continue
+		}
+
+		res[i].Location = &locs[j]
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+// healthz answers a GET to /healthz.
+func (h *Handler) healthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz answers a GET to /readyz.
+func (h *Handler) readyz(w http.ResponseWriter, _ *http.Request) {
+	if !h.Ready() {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "building"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+// parseCoord parses the lat and lon query parameters.
+func parseCoord(lat, lon string) (geom.Coord, error) {
+	y, err := strconv.ParseFloat(lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad lat %q", lat)
+	}
+
+	x, err := strconv.ParseFloat(lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad lon %q", lon)
+	}
+
+	return checkCoord(y, x)
+}
+
+// checkCoord checks that the coordinate is in range, written so that NaNs
+// aren't.
+func checkCoord(lat, lon float64) (geom.Coord, error) {
+	if !(lat >= -90 && lat <= 90) {
+		return nil, fmt.Errorf("lat %g out of range", lat)
+	}
+
+	if !(lon >= -180 && lon <= 180) {
+		return nil, fmt.Errorf("lon %g out of range", lon)
+	}
+
+	return geom.Coord{lon, lat}, nil
+}
+
+// writeJSON writes v as the JSON response.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	// The status has already been sent, so there's nothing to do about errors
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error response.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeohttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/sams96/rgeo"
+)
+
+func TestHandler(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ADMIN":"Testland","ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+		resp   string
+	}{
+		{
+			name:   "reverse",
+			method: http.MethodGet,
+			target: "/reverse?lat=1&lon=1",
+			status: http.StatusOK,
+			resp:   `{"country":"Testland","country_code_3":"TST"}`,
+		},
+		{
+			name:   "not found",
+			method: http.MethodGet,
+			target: "/reverse?lat=10&lon=10",
+			status: http.StatusNotFound,
+			resp:   `{"error":"country not found"}`,
+		},
+		{
+			name:   "bad lat",
+			method: http.MethodGet,
+			target: "/reverse?lon=1",
+			status: http.StatusBadRequest,
+			resp:   `{"error":"bad lat \"\""}`,
+		},
+		{
+			name:   "out of range",
+			method: http.MethodGet,
+			target: "/reverse?lat=1&lon=200",
+			status: http.StatusBadRequest,
+			resp:   `{"error":"lon 200 out of range"}`,
+		},
+		{
+			name:   "batch",
+			method: http.MethodPost,
+			target: "/reverse",
+			body:   `[{"lat":1,"lon":1},{"lat":10,"lon":10},{"lat":1},{"lat":-91,"lon":0}]`,
+			status: http.StatusOK,
+			resp: `[{"location":{"country":"Testland","country_code_3":"TST"}},` +
+				`{"error":"country not found"},{"error":"missing lat or lon"},` +
+				`{"error":"lat -91 out of range"}]`,
+		},
+		{
+			name:   "bad batch",
+			method: http.MethodPost,
+			target: "/reverse",
+			body:   `{"lat":1,"lon":1}`,
+			status: http.StatusBadRequest,
+			resp: `{"error":"bad request body: json: cannot unmarshal object into Go value ` +
+				`of type []rgeohttp.Coord"}`,
+		},
+		{
+			name:   "health",
+			method: http.MethodGet,
+			target: "/healthz",
+			status: http.StatusOK,
+			resp:   `{"status":"ok"}`,
+		},
+		{
+			name:   "ready",
+			method: http.MethodGet,
+			target: "/readyz",
+			status: http.StatusOK,
+			resp:   `{"status":"ready"}`,
+		},
+		{
+			name:   "wrong method",
+			method: http.MethodDelete,
+			target: "/reverse",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+
+	r, err := rgeo.NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(r)
+
+	for deadline := time.Now().Add(10 * time.Second); !h.Ready(); {
+		if time.Now().After(deadline) {
+			t.Fatal("handler not ready")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != test.status {
+				t.Errorf("expected status: %d\n got: %d\n", test.status, rec.Code)
+			}
+
+			if test.resp == "" {
+				return
+			}
+
+			if diff := deep.Equal(test.resp, strings.TrimSpace(rec.Body.String())); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
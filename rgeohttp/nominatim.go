@@ -0,0 +1,146 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeohttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sams96/rgeo"
+	"github.com/twpayne/go-geom"
+)
+
+// nominatimLicence is the licence given in Nominatim responses.
+const nominatimLicence = "Data from Natural Earth, public domain"
+
+// NominatimAddress is the "address" object of a Nominatim reverse response.
+type NominatimAddress struct {
+	City        string `json:"city,omitempty"`
+	County      string `json:"county,omitempty"`
+	State       string `json:"state,omitempty"`
+	StateCode   string `json:"ISO3166-2-lvl4,omitempty"`
+	Country     string `json:"country,omitempty"`
+	CountryCode string `json:"country_code,omitempty"`
+}
+
+// NominatimPlace is a Nominatim reverse response, with just the parts that
+// rgeo can fill in.
+type NominatimPlace struct {
+	Licence     string           `json:"licence"`
+	Lat         string           `json:"lat"`
+	Lon         string           `json:"lon"`
+	DisplayName string           `json:"display_name"`
+	Address     NominatimAddress `json:"address"`
+}
+
+// NewNominatimAddress converts a Location to a Nominatim address, where the
+// Province is the state and the District is the county. Codes that Natural
+// Earth doesn't have (which it gives as "-99") are left out.
+func NewNominatimAddress(l rgeo.Location) NominatimAddress {
+	return NominatimAddress{
+		City:        l.City,
+		County:      l.District,
+		State:       l.Province,
+		StateCode:   knownCode(l.ProvinceCode),
+		Country:     l.Country,
+		CountryCode: strings.ToLower(knownCode(l.CountryCode2)),
+	}
+}
+
+// NewNominatimPlace converts a Location to a Nominatim reverse response for
+// the given coordinate.
+func NewNominatimPlace(l rgeo.Location, coord geom.Coord) NominatimPlace {
+	addr := NewNominatimAddress(l)
+
+	var names []string
+
+	for _, s := range []string{addr.City, addr.County, addr.State, addr.Country} {
+		if s != "" {
+			names = append(names, s)
+		}
+	}
+
+	return NominatimPlace{
+		Licence:     nominatimLicence,
+		Lat:         strconv.FormatFloat(coord.Y(), 'f', -1, 64),
+		Lon:         strconv.FormatFloat(coord.X(), 'f', -1, 64),
+		DisplayName: strings.Join(names, ", "),
+		Address:     addr,
+	}
+}
+
+// WithNominatim makes GET requests to /reverse, and /reverse.php as older
+// versions of Nominatim used, answer in the same JSON as Nominatim's reverse
+// API, so that it can be used in place of a Nominatim server with the
+// existing client libraries. Only the json and jsonv2 formats are supported,
+// and json is used if no format is given. Batch requests are unaffected.
+func WithNominatim() Option {
+	return func(h *Handler) {
+		h.nominatim = true
+	}
+}
+
+// reverseNominatim answers a GET to /reverse in Nominatim mode.
+func (h *Handler) reverseNominatim(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	if f := q.Get("format"); f != "" && f != "json" && f != "jsonv2" {
+		writeNominatimError(w, fmt.Errorf("format %q isn't supported", f))
+		return
+	}
+
+	coord, err := parseCoord(q.Get("lat"), q.Get("lon"))
+	if err != nil {
+		writeNominatimError(w, err)
+		return
+	}
+
+	loc, err := h.r.ReverseGeocode(coord)
+	if errors.Is(err, rgeo.ErrLocationNotFound) {
+		// Nominatim doesn't treat this as an error
+		writeJSON(w, http.StatusOK, map[string]string{"error": "Unable to geocode"})
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, NewNominatimPlace(loc, coord))
+}
+
+// writeNominatimError writes a bad request error in the same form as
+// Nominatim.
+func writeNominatimError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		},
+	})
+}
+
+// knownCode returns the code, or nothing if it's Natural Earth's placeholder
+// for an unknown code.
+func knownCode(code string) string {
+	if code == "-99" {
+		return ""
+	}
+
+	return code
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeohttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/sams96/rgeo"
+)
+
+func TestNewNominatimPlace(t *testing.T) {
+	var testdata = []struct {
+		name     string
+		in       rgeo.Location
+		expected NominatimPlace
+	}{
+		{
+			name: "city",
+			in: rgeo.Location{
+				Country:      "Japan",
+				CountryCode2: "JP",
+				CountryCode3: "JPN",
+				Province:     "Hokkaidō",
+				ProvinceCode: "JP-01",
+				City:         "Sapporo",
+			},
+			expected: NominatimPlace{
+				Licence:     nominatimLicence,
+				Lat:         "43.07",
+				Lon:         "141.35",
+				DisplayName: "Sapporo, Hokkaidō, Japan",
+				Address: NominatimAddress{
+					City:        "Sapporo",
+					State:       "Hokkaidō",
+					StateCode:   "JP-01",
+					Country:     "Japan",
+					CountryCode: "jp",
+				},
+			},
+		},
+		{
+			name: "unknown codes",
+			in: rgeo.Location{
+				Country:      "Somaliland",
+				CountryCode2: "-99",
+				District:     "Somewhere",
+			},
+			expected: NominatimPlace{
+				Licence:     nominatimLicence,
+				Lat:         "43.07",
+				Lon:         "141.35",
+				DisplayName: "Somewhere, Somaliland",
+				Address: NominatimAddress{
+					County:  "Somewhere",
+					Country: "Somaliland",
+				},
+			},
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			res := NewNominatimPlace(test.in, []float64{141.35, 43.07})
+			if diff := deep.Equal(test.expected, res); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestHandler_Nominatim(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ADMIN":"Testland","ISO_A2":"TS","name":"Testshire","iso_3166_2":"TS-01"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name   string
+		target string
+		status int
+		resp   string
+	}{
+		{
+			name:   "reverse",
+			target: "/reverse?format=json&lat=1&lon=1.5",
+			status: http.StatusOK,
+			resp: `{"licence":"` + nominatimLicence + `","lat":"1","lon":"1.5",` +
+				`"display_name":"Testshire, Testland","address":{"state":"Testshire",` +
+				`"ISO3166-2-lvl4":"TS-01","country":"Testland","country_code":"ts"}}`,
+		},
+		{
+			name:   "php",
+			target: "/reverse.php?format=jsonv2&lat=1&lon=1.5",
+			status: http.StatusOK,
+			resp: `{"licence":"` + nominatimLicence + `","lat":"1","lon":"1.5",` +
+				`"display_name":"Testshire, Testland","address":{"state":"Testshire",` +
+				`"ISO3166-2-lvl4":"TS-01","country":"Testland","country_code":"ts"}}`,
+		},
+		{
+			name:   "not found",
+			target: "/reverse?lat=10&lon=10",
+			status: http.StatusOK,
+			resp:   `{"error":"Unable to geocode"}`,
+		},
+		{
+			name:   "unsupported format",
+			target: "/reverse?format=xml&lat=1&lon=1",
+			status: http.StatusBadRequest,
+			resp:   `{"error":{"code":400,"message":"format \"xml\" isn't supported"}}`,
+		},
+		{
+			name:   "bad lon",
+			target: "/reverse?lat=1&lon=x",
+			status: http.StatusBadRequest,
+			resp:   `{"error":{"code":400,"message":"bad lon \"x\""}}`,
+		},
+	}
+
+	r, err := rgeo.NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(r, WithNominatim())
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.target, nil))
+
+			if rec.Code != test.status {
+				t.Errorf("expected status: %d\n got: %d\n", test.status, rec.Code)
+			}
+
+			if diff := deep.Equal(test.resp, strings.TrimSpace(rec.Body.String())); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestHandler_Nominatim_Countries(t *testing.T) {
+	r, err := rgeo.New(rgeo.Countries110)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewHandler(r, WithNominatim())
+
+	// France's ISO_A2 is -99 in Natural Earth, but it still has a code
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reverse?format=json&lat=46.5&lon=2.5", nil))
+
+	var place NominatimPlace
+	if err := json.Unmarshal(rec.Body.Bytes(), &place); err != nil {
+		t.Fatal(err)
+	}
+
+	if place.Address.Country != "France" || place.Address.CountryCode != "fr" {
+		t.Errorf("expected: France, fr\n got: %s, %s\n", place.Address.Country, place.Address.CountryCode)
+	}
+}
@@ -0,0 +1,378 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/golang/geo/s2"
+	"github.com/twpayne/go-geom"
+)
+
+// The snapshot format is:
+//
+//	magic   "rgeo"
+//	version uvarint
+//	count   uvarint
+//
+// followed by count features, in the order they were added to the index:
+//
+//	dataset    uvarint
+//	feature    uvarint
+//	nstrings   uvarint, followed by that many strings in the order of
+//	           locationFields
+//	properties uvarint length, followed by that many bytes of JSON
+//	names      uvarint length, followed by that many bytes of JSON
+//	polygon    s2.Polygon encoding
+//
+// and then the populated places:
+//
+//	count      uvarint
+//	name       string
+//	population uvarint
+//	longitude  uvarint of the float64 bits
+//	latitude   uvarint of the float64 bits
+//
+// where each string is a uvarint length followed by that many bytes. Writing
+// the number of strings means that fields can be added to the end of Location
+// without breaking older snapshots.
+const (
+	snapshotMagic   = "rgeo"
+	snapshotVersion = 1
+
+	// maxSnapshotLen stops a corrupt snapshot from making Load allocate
+	// huge amounts of memory.
+	maxSnapshotLen = 1 << 28
+)
+
+// ErrBadSnapshot is returned by Load when the data isn't a valid snapshot.
+var ErrBadSnapshot = errors.New("bad snapshot")
+
+// Save writes a binary snapshot of the polygons and Locations in r, which can
+// be read back with Load. This skips decompressing and parsing the GeoJSON and
+// converting it into s2 polygons, but not building the shape index, see Load.
+// The snapshot is written uncompressed, but Load will accept it gzip
+// compressed as well.
+func (r *Rgeo) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString(snapshotMagic); err != nil {
+		return err
+	}
+
+	writeUvarint(bw, snapshotVersion)
+	writeUvarint(bw, uint64(r.index.Len()))
+
+	for id := 0; id < r.index.Len(); id++ {
+		shape := r.index.Shape(int32(id))
+
+		p, ok := shape.(*s2.Polygon)
+		if !ok {
+			return fmt.Errorf("can't save shape %d of type %T", id, shape)
+		}
+
+		m := r.locs[shape]
+
+		writeUvarint(bw, uint64(m.Dataset))
+		writeUvarint(bw, uint64(m.Feature))
+
+		fields := locationFields(&m.Location)
+		writeUvarint(bw, uint64(len(fields)))
+
+		for _, f := range fields {
+			writeString(bw, *f)
+		}
+
+		var props []byte
+		if m.Location.props != nil {
+			var err error
+			if props, err = json.Marshal(m.Location.props.m); err != nil {
+				return fmt.Errorf("failed to encode properties of shape %d: %w", id, err)
+			}
+		}
+
+		writeString(bw, string(props))
+
+		var names []byte
+		if m.Location.names != nil {
+			var err error
+			if names, err = json.Marshal(m.Location.names.m); err != nil {
+				return fmt.Errorf("failed to encode names of shape %d: %w", id, err)
+			}
+		}
+
+		writeString(bw, string(names))
+
+		if err := p.Encode(bw); err != nil {
+			return fmt.Errorf("failed to encode shape %d: %w", id, err)
+		}
+	}
+
+	writeUvarint(bw, uint64(len(r.places)))
+
+	for _, p := range r.places {
+		writeString(bw, p.Name)
+		writeUvarint(bw, uint64(max(p.Population, 0)))
+		writeUvarint(bw, math.Float64bits(p.Coord.X()))
+		writeUvarint(bw, math.Float64bits(p.Coord.Y()))
+	}
+
+	return bw.Flush()
+}
+
+// Load reads a snapshot written by Save, which may be gzip compressed, and
+// returns an Rgeo ready to be used with ReverseGeocode. Note that the shape
+// index itself isn't part of the snapshot, so it is still built on the first
+// lookup or when calling Build. With the larger datasets this takes longer than
+// Load itself, so a snapshot cuts the start up time without getting rid of it.
+//
+// Only the options that don't change how the GeoJSON is read, WithCellCache and
+// WithGeometry, have any effect. The others were applied when the snapshot's
+// Rgeo was created, and datasets can't be added to a snapshot.
+func Load(rd io.Reader, opts ...Option) (*Rgeo, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if len(o.sources) > 0 {
+		return nil, errors.New("can't add datasets to a snapshot")
+	}
+
+	br := bufio.NewReader(rd)
+
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("decompression failed for snapshot: %w", err)
+		}
+
+		defer zr.Close()
+
+		br = bufio.NewReader(zr)
+	}
+
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != snapshotMagic {
+		return nil, fmt.Errorf("%w: missing header", ErrBadSnapshot)
+	}
+
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadSnapshot, err)
+	}
+
+	if version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrBadSnapshot, version)
+	}
+
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrBadSnapshot, err)
+	}
+
+	ret := newRgeo(options{cellLevel: o.cellLevel, geometry: o.geometry})
+
+	for id := uint64(0); id < n; id++ {
+		m, p, err := readSnapshotFeature(br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: shape %d: %w", ErrBadSnapshot, id, err)
+		}
+
+		if o.geometry {
+			m.Location.poly = &polygon{p: p}
+		}
+
+		ret.index.Add(p)
+		ret.locs[p] = m
+	}
+
+	if ret.places, err = readSnapshotPlaces(br); err != nil {
+		return nil, fmt.Errorf("%w: places: %w", ErrBadSnapshot, err)
+	}
+
+	return ret, nil
+}
+
+// readSnapshotFeature reads a single feature from a snapshot.
+func readSnapshotFeature(br *bufio.Reader) (Match, *s2.Polygon, error) {
+	var m Match
+
+	dataset, err := binary.ReadUvarint(br)
+	if err != nil {
+		return m, nil, err
+	}
+
+	feature, err := binary.ReadUvarint(br)
+	if err != nil {
+		return m, nil, err
+	}
+
+	m.Dataset, m.Feature = int(dataset), int(feature)
+
+	nfields, err := binary.ReadUvarint(br)
+	if err != nil {
+		return m, nil, err
+	}
+
+	fields := locationFields(&m.Location)
+
+	for i := uint64(0); i < nfields; i++ {
+		s, err := readString(br)
+		if err != nil {
+			return m, nil, err
+		}
+
+		// Ignore fields from newer versions that we don't know about
+		if i < uint64(len(fields)) {
+			*fields[i] = s
+		}
+	}
+
+	props, err := readString(br)
+	if err != nil {
+		return m, nil, err
+	}
+
+	if props != "" {
+		var pm map[string]interface{}
+		if err := json.Unmarshal([]byte(props), &pm); err != nil {
+			return m, nil, err
+		}
+
+		m.Location.props = &properties{m: pm}
+	}
+
+	names, err := readString(br)
+	if err != nil {
+		return m, nil, err
+	}
+
+	if names != "" {
+		var nm map[string]localName
+		if err := json.Unmarshal([]byte(names), &nm); err != nil {
+			return m, nil, err
+		}
+
+		m.Location.names = &localNames{m: nm}
+	}
+
+	// br is an io.ByteReader so Decode doesn't read past the polygon
+	p := new(s2.Polygon)
+	if err := p.Decode(br); err != nil {
+		return m, nil, err
+	}
+
+	return m, p, nil
+}
+
+// readSnapshotPlaces reads the populated places from a snapshot.
+func readSnapshotPlaces(br *bufio.Reader) ([]Place, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > maxSnapshotLen {
+		return nil, fmt.Errorf("too many places (%d)", n)
+	}
+
+	places := make([]Place, 0, n)
+
+	for i := uint64(0); i < n; i++ {
+		var (
+			p    Place
+			vals [3]uint64
+		)
+
+		if p.Name, err = readString(br); err != nil {
+			return nil, err
+		}
+
+		for j := range vals {
+			if vals[j], err = binary.ReadUvarint(br); err != nil {
+				return nil, err
+			}
+		}
+
+		p.Population = int(vals[0])
+		p.Coord = geom.Coord{math.Float64frombits(vals[1]), math.Float64frombits(vals[2])}
+		places = append(places, p)
+	}
+
+	return places, nil
+}
+
+// locationFields returns pointers to the string fields of l, in the order they
+// are written to snapshots. New fields must only be added to the end.
+func locationFields(l *Location) []*string {
+	return []*string{
+		&l.Country,
+		&l.CountryLong,
+		&l.CountryCode2,
+		&l.CountryCode3,
+		&l.Continent,
+		&l.Region,
+		&l.SubRegion,
+		&l.Province,
+		&l.ProvinceCode,
+		&l.City,
+		&l.TimeZone,
+		&l.WaterBody,
+		&l.MaritimeCountryCode3,
+		&l.MaritimeZoneType,
+		&l.District,
+	}
+}
+
+// writeUvarint writes x to w as a uvarint. Errors are picked up by the final
+// Flush of the bufio.Writer.
+func writeUvarint(w *bufio.Writer, x uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	_, _ = w.Write(buf[:binary.PutUvarint(buf[:], x)])
+}
+
+// writeString writes the length of s followed by s.
+func writeString(w *bufio.Writer, s string) {
+	writeUvarint(w, uint64(len(s)))
+	_, _ = w.WriteString(s)
+}
+
+// readString reads a string written by writeString.
+func readString(br *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+
+	if n > maxSnapshotLen {
+		return "", fmt.Errorf("string too long (%d bytes)", n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
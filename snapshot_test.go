@@ -0,0 +1,205 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestSaveLoad(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST","ADMIN":"Testland","NAME_DE":"Testreich","POP_EST":1000},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]],
+						[[0.5,0.5],[0.5,1],[1,1],[1,0.5],[0.5,0.5]]]}},
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TSU"},
+				"geometry":{"type":"MultiPolygon",
+					"coordinates":[[[[3,0],[4,0],[4,1],[3,1],[3,0]]],
+						[[[5,0],[6,0],[6,1],[5,1],[5,0]]]]}}
+			]
+		}`
+
+	orig, err := NewWithOptions(
+		WithReaders(strings.NewReader(testgeo)),
+		WithProperties(),
+		WithLanguages("de"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plain bytes.Buffer
+	if err := orig.Save(&plain); err != nil {
+		t.Fatal(err)
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if err := orig.Save(zw); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	points := [][]float64{
+		{1.5, 1.5},   // in
+		{0.75, 0.75}, // in hole
+		{3.5, 0.5},   // in first part of multipolygon
+		{5.5, 0.5},   // in second part of multipolygon
+		{4.5, 0.5},   // between
+	}
+
+	for name, data := range map[string][]byte{
+		"plain": plain.Bytes(),
+		"gzip":  compressed.Bytes(),
+	} {
+		data := data
+
+		t.Run(name, func(t *testing.T) {
+			loaded, err := Load(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, p := range points {
+				expected, expectedErr := orig.ReverseGeocodeAll(p)
+				result, err := loaded.ReverseGeocodeAll(p)
+				if err != expectedErr {
+					t.Errorf("%v: expected error: %s\n got: %s\n", p, expectedErr, err)
+				}
+				if diff := deep.Equal(expected, result); diff != nil {
+					t.Error(p, diff)
+				}
+			}
+
+			loc, err := loaded.ReverseGeocode([]float64{1.5, 1.5})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v, _ := loc.Property("POP_EST"); v != 1000.0 {
+				t.Errorf("expected POP_EST: 1000\n got: %v\n", v)
+			}
+			if c := loc.Localized("de").Country; c != "Testreich" {
+				t.Errorf("expected: Testreich\n got: %s\n", c)
+			}
+		})
+	}
+}
+
+func TestLoad_BadData(t *testing.T) {
+	testdata := []struct {
+		name string
+		in   string
+	}{
+		{name: "Empty", in: ""},
+		{name: "Wrong magic", in: "nope"},
+		{name: "Wrong version", in: "rgeo\x7f\x00"},
+		{name: "Truncated", in: "rgeo\x01\x01\x00\x00\x0a"},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Load(strings.NewReader(test.in))
+			if !errors.Is(err, ErrBadSnapshot) {
+				t.Errorf("expected error: %s\n got: %s\n", ErrBadSnapshot, err)
+			}
+		})
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	r, err := New(Countries110)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Save(&buf); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		// Include building the index, which Load leaves until later
+		r, err := Load(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		r.Build()
+	}
+}
+
+func TestLoad_Options(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}}
+			]
+		}`
+
+	orig, err := NewFromReaders(strings.NewReader(testgeo))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := orig.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := Load(bytes.NewReader(buf.Bytes()), WithCellCache(10), WithGeometry())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded.Build()
+
+	if loaded.cache == nil || len(loaded.cache.cells) == 0 {
+		t.Error("expected a cell cache")
+	}
+
+	loc, err := loaded.ReverseGeocode([]float64{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loc.CountryCode3 != "TST" || loc.Geometry() == nil {
+		t.Errorf("expected TST with geometry\n got: %s, %v\n", loc.CountryCode3, loc.Geometry())
+	}
+
+	// Datasets can't be added to a snapshot
+	if _, err := Load(bytes.NewReader(buf.Bytes()), WithDatasets(Countries110)); err == nil {
+		t.Error("expected error for datasets with Load")
+	}
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twpayne/go-geom"
+)
+
+// ErrTimeZoneNotFound is returned when the Location for the given coordinates
+// doesn't have a time zone, usually because no time zone dataset was used.
+var ErrTimeZoneNotFound = errors.New("time zone not found")
+
+// TimeLocation returns the *time.Location for the TimeZone of l. This uses
+// time.LoadLocation, so needs the IANA time zone database on the system, or
+// the time/tzdata package imported into the program.
+func (l Location) TimeLocation() (*time.Location, error) {
+	if l.TimeZone == "" {
+		return nil, ErrTimeZoneNotFound
+	}
+
+	tz, err := time.LoadLocation(l.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time zone %q: %w", l.TimeZone, err)
+	}
+
+	return tz, nil
+}
+
+// ReverseGeocodeTimeZone returns the *time.Location for the time zone
+// containing the given coordinate, which needs a dataset with a "tzid" property,
+// such as one generated from timezone-boundary-builder as described in the
+// README. See Location.TimeLocation.
+func (r *Rgeo) ReverseGeocodeTimeZone(loc geom.Coord) (*time.Location, error) {
+	l, err := r.ReverseGeocode(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.TimeLocation()
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReverseGeocodeTimeZone(t *testing.T) {
+	testgeo := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ISO_A3":"TST"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[4,0],[4,2],[0,2],[0,0]]]}}
+			]
+		}`
+
+	testzones := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"tzid":"Europe/London"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"tzid":"Asia/Tokyo"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[2,0],[4,0],[4,2],[2,2],[2,0]]]}}
+			]
+		}`
+
+	var testdata = []struct {
+		name     string
+		in       []float64
+		expected string
+		err      error
+	}{
+		{
+			name:     "London",
+			in:       []float64{1, 1},
+			expected: "Europe/London",
+		},
+		{
+			name:     "Tokyo",
+			in:       []float64{3, 1},
+			expected: "Asia/Tokyo",
+		},
+		{
+			name: "Outside",
+			in:   []float64{10, 10},
+			err:  ErrLocationNotFound,
+		},
+	}
+
+	r, err := New(
+		func() []byte { return compressData(t, testgeo) },
+		func() []byte { return compressData(t, testzones) },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			loc, err := r.ReverseGeocode(test.in)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+			if loc.TimeZone != test.expected {
+				t.Errorf("expected: %s\n got: %s\n", test.expected, loc.TimeZone)
+			}
+			if err == nil && loc.CountryCode3 != "TST" {
+				t.Errorf("expected country to be kept, got: %v", loc)
+			}
+
+			tz, err := r.ReverseGeocodeTimeZone(test.in)
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+			}
+			if err == nil && tz.String() != test.expected {
+				t.Errorf("expected: %s\n got: %s\n", test.expected, tz)
+			}
+		})
+	}
+}
+
+func TestLocation_TimeLocation(t *testing.T) {
+	if _, err := (Location{Country: "Testland"}).TimeLocation(); !errors.Is(err, ErrTimeZoneNotFound) {
+		t.Errorf("expected error: %s\n got: %s\n", ErrTimeZoneNotFound, err)
+	}
+
+	if _, err := (Location{TimeZone: "Not/AZone"}).TimeLocation(); err == nil {
+		t.Error("expected error for unknown time zone")
+	}
+}
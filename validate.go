@@ -0,0 +1,257 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"fmt"
+
+	"github.com/golang/geo/s2"
+)
+
+// ValidationMode sets what NewWithOptions does with features whose geometry
+// isn't valid, see WithValidation.
+type ValidationMode int
+
+const (
+	// ValidationNone only fails on geometry that can't be converted to s2
+	// polygons at all, which is the default. Polygons that s2 accepts but
+	// that aren't valid, such as self-intersecting rings, are added as they
+	// are and may give wrong answers.
+	ValidationNone ValidationMode = iota
+
+	// ValidationFail fails with a FeatureError on the first invalid feature.
+	ValidationFail
+
+	// ValidationSkip leaves invalid features out of the index, and records
+	// them in ValidationIssues.
+	ValidationSkip
+
+	// ValidationRepair fixes what it can by closing unclosed rings, removing
+	// duplicate vertices and dropping degenerate rings, then skips the
+	// features that are still invalid. Both are recorded in
+	// ValidationIssues.
+	ValidationRepair
+)
+
+// WithValidation checks the geometry of every feature against the s2 validity
+// rules as it is added: rings must have at least three distinct vertices, no
+// vertex can appear twice, no edges can cross, either within a ring or
+// between the rings of a feature, and the polygons of a MultiPolygon can't be
+// inside one another. The mode sets what happens to the features that fail.
+//
+// This makes NewWithOptions slower, so is best used when generating datasets
+// or snapshots, rather than every time the program starts.
+func WithValidation(mode ValidationMode) Option {
+	return func(o *options) {
+		o.validation = mode
+	}
+}
+
+// ValidationIssue is a problem found in the geometry of a feature.
+type ValidationIssue struct {
+	// Index of the dataset and of the feature within it, as in Match
+	Dataset int
+	Feature int
+
+	// Name of the feature, from its Location
+	Name string
+
+	// What was wrong with the feature, or what was done to fix it
+	Reason string
+
+	// Whether the feature was left out of the index
+	Skipped bool
+}
+
+// String returns a description of the issue.
+func (v ValidationIssue) String() string {
+	action := "repaired"
+	if v.Skipped {
+		action = "skipped"
+	}
+
+	return fmt.Sprintf("%s feature %d%s in dataset %d: %s",
+		action, v.Feature, quoteName(v.Name), v.Dataset, v.Reason)
+}
+
+// ValidationIssues returns the problems found when adding the datasets, in
+// the order they were found. This is always empty unless WithValidation is
+// used with ValidationSkip or ValidationRepair.
+func (r *Rgeo) ValidationIssues() []ValidationIssue {
+	return append([]ValidationIssue(nil), r.issues...)
+}
+
+// FeatureError is returned by New and NewWithOptions when a feature has bad
+// geometry, saying which feature it was.
+type FeatureError struct {
+	// Index of the dataset and of the feature within it, as in Match
+	Dataset int
+	Feature int
+
+	// Name of the feature, from its Location
+	Name string
+
+	Err error
+}
+
+func (e *FeatureError) Error() string {
+	return fmt.Sprintf("bad polygon in geometry of feature %d%s in dataset %d: %s",
+		e.Feature, quoteName(e.Name), e.Dataset, e.Err)
+}
+
+func (e *FeatureError) Unwrap() error {
+	return e.Err
+}
+
+// newValidationIssue returns a ValidationIssue for the feature m.
+func newValidationIssue(m Match, reason string, skipped bool) ValidationIssue {
+	return ValidationIssue{
+		Dataset: m.Dataset,
+		Feature: m.Feature,
+		Name:    featureName(m.Location),
+		Reason:  reason,
+		Skipped: skipped,
+	}
+}
+
+// invalidFeature handles a feature with bad geometry according to the
+// validation mode, returning an error if loading should stop.
+func (r *Rgeo) invalidFeature(m Match, err error) error {
+	switch r.opts.validation {
+	case ValidationSkip, ValidationRepair:
+		r.issues = append(r.issues, newValidationIssue(m, err.Error(), true))
+
+		return nil
+	}
+
+	return &FeatureError{
+		Dataset: m.Dataset,
+		Feature: m.Feature,
+		Name:    featureName(m.Location),
+		Err:     err,
+	}
+}
+
+// validatePolygon checks p against the s2 validity rules, including the
+// duplicate vertices and crossing edges that s2.Polygon.Validate doesn't check
+// for yet.
+func validatePolygon(p *s2.Polygon) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	for i, l := range p.Loops() {
+		seen := make(map[s2.Point]int, l.NumVertices())
+
+		for j, v := range l.Vertices() {
+			if k, ok := seen[v]; ok {
+				return fmt.Errorf("loop %d: vertices %d and %d are the same", i, k, j)
+			}
+
+			seen[v] = j
+		}
+	}
+
+	index := s2.NewShapeIndex()
+	index.Add(p)
+
+	query := s2.NewCrossingEdgeQuery(index)
+
+	for e := 0; e < p.NumEdges(); e++ {
+		edge := p.Edge(e)
+
+		crossings := query.Crossings(edge.V0, edge.V1, p, s2.CrossingTypeInterior)
+		if len(crossings) == 0 {
+			continue
+		}
+
+		a, b := p.ChainPosition(e).ChainID, p.ChainPosition(crossings[0]).ChainID
+		if a == b {
+			return fmt.Errorf("loop %d: crosses itself", a)
+		}
+
+		return fmt.Errorf("loops %d and %d cross", a, b)
+	}
+
+	return nil
+}
+
+// checkShells checks that none of the exterior rings of p ended up as holes.
+// s2 takes any loop inside another as a hole, so a polygon of a MultiPolygon
+// that is inside another one takes a piece out of it, rather than adding to
+// it. A polygon on an island in a lake of another is fine, since that ends up
+// as a shell again.
+//
+// When repairing these polygons are dropped along with their holes, since the
+// one they're inside already covers them.
+func (c *conversion) checkShells(p *s2.Polygon) (*s2.Polygon, error) {
+	for {
+		nested := -1
+
+		for i, l := range p.Loops() {
+			if c.rings[l].shell && l.IsHole() {
+				nested = i
+				break
+			}
+		}
+
+		if nested < 0 {
+			return p, nil
+		}
+
+		part := c.rings[p.Loop(nested)].part
+		if !c.repair {
+			return nil, fmt.Errorf("polygon %d is inside another polygon", part)
+		}
+
+		c.repaired("dropped polygon inside another polygon")
+
+		var loops []*s2.Loop
+
+		for i, l := range p.Loops() {
+			// Its holes are the loops inside it from the same polygon
+			inside := i > nested && i <= p.LastDescendant(nested)
+			if i == nested || (inside && c.rings[l] == ring{part: part}) {
+				continue
+			}
+
+			loops = append(loops, l)
+		}
+
+		// The loops have already been made nestable, and have the right
+		// orientation whether they were oriented or not
+		p = s2.PolygonFromLoops(loops)
+	}
+}
+
+// featureName returns the most specific name in l, to identify a feature in
+// errors. Features from datasets without country names, such as time zones or
+// water bodies, fall back to the names they do have.
+func featureName(l Location) string {
+	return firstNonEmpty(l.City, l.District, l.Province, l.Country,
+		l.CountryLong, l.CountryCode3, l.CountryCode2, l.WaterBody, l.TimeZone,
+		l.MaritimeCountryCode3)
+}
+
+// quoteName returns the name in quotes with a leading space, or nothing if
+// there isn't a name.
+func quoteName(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" %q", name)
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+// testValidateGeo has one valid feature followed by invalid ones, with the
+// names saying what is wrong with them.
+const testValidateGeo = `{
+	"type":"FeatureCollection",
+	"features":[
+		{"type":"Feature",
+		"properties":{"ADMIN":"Valid"},
+		"geometry":{"type":"Polygon",
+			"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Bowtie"},
+		"geometry":{"type":"Polygon",
+			"coordinates":[[[10,0],[12,2],[12,0],[10,2],[10,0]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Spike"},
+		"geometry":{"type":"Polygon",
+			"coordinates":[[[20,0],[22,0],[23,1],[22,0],[22,2],[20,2],[20,0]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Figure eight"},
+		"geometry":{"type":"Polygon",
+			"coordinates":[[[30,0],[31,0],[31,1],[32,1],[32,2],[31,2],[31,1],[30,1],[30,0]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Unclosed"},
+		"geometry":{"type":"Polygon",
+			"coordinates":[[[40,0],[42,0],[42,2],[40,2]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Short hole"},
+		"geometry":{"type":"Polygon",
+			"coordinates":[[[50,0],[52,0],[52,2],[50,2],[50,0]],[[51,1],[51.5,1],[51,1]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Overlapping"},
+		"geometry":{"type":"MultiPolygon",
+			"coordinates":[[[[60,0],[62,0],[62,2],[60,2],[60,0]]],
+				[[[61,1],[63,1],[63,3],[61,3],[61,1]]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Nested"},
+		"geometry":{"type":"MultiPolygon",
+			"coordinates":[[[[70,0],[80,0],[80,10],[70,10],[70,0]]],
+				[[[72,2],[74,2],[74,4],[72,4],[72,2]],[[72.5,2.5],[72.5,3],[73,3],[73,2.5],[72.5,2.5]]]]}},
+		{"type":"Feature",
+		"properties":{"ADMIN":"Island"},
+		"geometry":{"type":"MultiPolygon",
+			"coordinates":[[[[90,0],[100,0],[100,10],[90,10],[90,0]],
+					[[92,2],[92,8],[98,8],[98,2],[92,2]]],
+				[[[94,4],[96,4],[96,6],[94,6],[94,4]]]]}}
+	]
+}`
+
+func TestWithValidation(t *testing.T) {
+	var testdata = []struct {
+		name     string
+		mode     ValidationMode
+		err      string
+		issues   []ValidationIssue
+		in       []float64
+		expected string
+	}{
+		{
+			name: "fail",
+			mode: ValidationFail,
+			err:  `bad polygon in geometry of feature 1 "Bowtie" in dataset 0: loop 0: crosses itself`,
+		},
+		{
+			name: "skip",
+			mode: ValidationSkip,
+			issues: []ValidationIssue{
+				{Feature: 1, Name: "Bowtie", Reason: "loop 0: crosses itself", Skipped: true},
+				{Feature: 2, Name: "Spike", Reason: "loop 0: vertices 2 and 4 are the same", Skipped: true},
+				{Feature: 3, Name: "Figure eight", Reason: "loop 0: vertices 3 and 7 are the same", Skipped: true},
+				{
+					Feature: 4, Name: "Unclosed", Skipped: true,
+					Reason: "last coordinate not same as first for polygon: [40 0 42 0 42 2 40 2]",
+				},
+				{
+					Feature: 5, Name: "Short hole", Skipped: true,
+					Reason: "can't convert ring with less than 4 points",
+				},
+				{Feature: 6, Name: "Overlapping", Reason: "loops 0 and 1 cross", Skipped: true},
+				{Feature: 7, Name: "Nested", Reason: "polygon 1 is inside another polygon", Skipped: true},
+			},
+			in:       []float64{21, 1},
+			expected: "",
+		},
+		{
+			name: "repair",
+			mode: ValidationRepair,
+			issues: []ValidationIssue{
+				{Feature: 1, Name: "Bowtie", Reason: "loop 0: crosses itself", Skipped: true},
+				{Feature: 2, Name: "Spike", Reason: "removed duplicate vertices"},
+				{Feature: 2, Name: "Spike", Reason: "dropped degenerate ring"},
+				{Feature: 3, Name: "Figure eight", Reason: "removed duplicate vertices"},
+				{Feature: 4, Name: "Unclosed", Reason: "closed unclosed ring"},
+				{Feature: 5, Name: "Short hole", Reason: "dropped ring with less than 4 points"},
+				{Feature: 6, Name: "Overlapping", Reason: "loops 0 and 1 cross", Skipped: true},
+				{Feature: 7, Name: "Nested", Reason: "dropped polygon inside another polygon"},
+			},
+			in:       []float64{21, 1},
+			expected: "Spike",
+		},
+		{
+			name: "repair nested",
+			mode: ValidationRepair,
+			issues: []ValidationIssue{
+				{Feature: 1, Name: "Bowtie", Reason: "loop 0: crosses itself", Skipped: true},
+				{Feature: 2, Name: "Spike", Reason: "removed duplicate vertices"},
+				{Feature: 2, Name: "Spike", Reason: "dropped degenerate ring"},
+				{Feature: 3, Name: "Figure eight", Reason: "removed duplicate vertices"},
+				{Feature: 4, Name: "Unclosed", Reason: "closed unclosed ring"},
+				{Feature: 5, Name: "Short hole", Reason: "dropped ring with less than 4 points"},
+				{Feature: 6, Name: "Overlapping", Reason: "loops 0 and 1 cross", Skipped: true},
+				{Feature: 7, Name: "Nested", Reason: "dropped polygon inside another polygon"},
+			},
+			in:       []float64{72.75, 2.75},
+			expected: "Nested",
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewWithOptions(
+				WithReaders(strings.NewReader(testValidateGeo)),
+				WithValidation(test.mode),
+			)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("expected error: %s\n got: %s\n", test.err, err)
+				}
+
+				var fe *FeatureError
+				if !errors.As(err, &fe) || fe.Feature != 1 {
+					t.Errorf("expected FeatureError for feature 1, got: %#v", err)
+				}
+
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := deep.Equal(test.issues, r.ValidationIssues()); diff != nil {
+				t.Error(diff)
+			}
+
+			loc, _ := r.ReverseGeocode([]float64{1, 1})
+			if loc.Country != "Valid" {
+				t.Errorf("expected valid feature to be added, got: %v", loc)
+			}
+
+			// An island in a lake isn't a polygon inside another
+			loc, _ = r.ReverseGeocode([]float64{95, 5})
+			if loc.Country != "Island" {
+				t.Errorf("expected island to be added, got: %v", loc)
+			}
+
+			loc, _ = r.ReverseGeocode(test.in)
+			if loc.Country != test.expected {
+				t.Errorf("expected: %q\n got: %q\n", test.expected, loc.Country)
+			}
+		})
+	}
+}
+
+func TestWithValidation_None(t *testing.T) {
+	// The default only fails on the geometry that can't be converted
+	_, err := NewWithOptions(WithReaders(strings.NewReader(testValidateGeo)))
+
+	expected := `bad polygon in geometry of feature 4 "Unclosed" in dataset 0: ` +
+		"last coordinate not same as first for polygon: [40 0 42 0 42 2 40 2]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error: %s\n got: %s\n", expected, err)
+	}
+}
+
+func TestWithValidation_Countries110(t *testing.T) {
+	// Natural Earth has a couple of rings that cross themselves, the one in
+	// the USA is from two almost identical points on the Alaska border.
+	r, err := NewWithOptions(WithDatasets(Countries110), WithValidation(ValidationSkip))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var skipped []string
+	for _, issue := range r.ValidationIssues() {
+		skipped = append(skipped, issue.Name)
+	}
+
+	if diff := deep.Equal([]string{"United States of America", "Sudan"}, skipped); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestFeatureName(t *testing.T) {
+	var testdata = []struct {
+		name     string
+		in       Location
+		expected string
+	}{
+		{
+			name:     "city",
+			in:       Location{Country: "Testland", Province: "Testshire", City: "Testville"},
+			expected: "Testville",
+		},
+		{
+			name:     "district",
+			in:       Location{Country: "Testland", Province: "Testshire", District: "Test County"},
+			expected: "Test County",
+		},
+		{
+			name:     "country code",
+			in:       Location{CountryCode2: "TS"},
+			expected: "TS",
+		},
+		{
+			name:     "water body",
+			in:       Location{WaterBody: "Test Sea"},
+			expected: "Test Sea",
+		},
+		{
+			name:     "time zone",
+			in:       Location{TimeZone: "Europe/London"},
+			expected: "Europe/London",
+		},
+		{
+			name:     "maritime",
+			in:       Location{MaritimeCountryCode3: "TST", MaritimeZoneType: "12NM"},
+			expected: "TST",
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if diff := deep.Equal(test.expected, featureName(test.in)); diff != nil {
+				t.Error(diff)
+			}
+		})
+	}
+}
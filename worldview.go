@@ -0,0 +1,146 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"strings"
+
+	"github.com/golang/geo/s2"
+)
+
+// WithWorldview assigns disputed territory to countries according to the
+// point of view of the given country, e.g. "IND" for India, instead of who
+// controls it on the ground. This uses the "ADM0_A3_xx" properties that
+// Natural Earth has for each of its points of view, where xx is a two letter
+// code (also accepted here) that is mostly, but not always, the ISO 3166-1
+// alpha-2 code.
+//
+// The included datasets only draw the borders as they are on the ground, so
+// some disputes (e.g. Western Sahara) can be resolved with them alone, but most
+// need the Natural Earth disputed areas as well, generated with datagen as
+// described in the README and given after the countries so that without a
+// worldview the countries take precedence.
+func WithWorldview(pov string) Option {
+	return func(o *options) {
+		o.worldview = worldviewCode(pov)
+	}
+}
+
+// worldviews maps the country codes used by the Natural Earth point of view
+// files to the suffixes of the point of view properties.
+var worldviews = map[string]string{
+	"ARG": "AR", "BGD": "BD", "BRA": "BR", "CHN": "CN", "DEU": "DE",
+	"EGY": "EG", "ESP": "ES", "FRA": "FR", "GBR": "GB", "GRC": "GR",
+	"IDN": "ID", "IND": "IN", "ISR": "IL", "ITA": "IT", "JPN": "JP",
+	"KOR": "KO", "MAR": "MA", "NLD": "NL", "NPL": "NP", "PAK": "PK",
+	"POL": "PL", "PRT": "PT", "PSE": "PS", "RUS": "RU", "SAU": "SA",
+	"SWE": "SE", "TUR": "TR", "TWN": "TW", "UKR": "UA", "USA": "US",
+	"VNM": "VN",
+}
+
+// worldviewCode returns the suffix of the point of view properties for the
+// given country code.
+func worldviewCode(pov string) string {
+	pov = strings.ToUpper(pov)
+	if code, ok := worldviews[pov]; ok {
+		return code
+	}
+
+	return pov
+}
+
+// worldview collects what's needed to apply WithWorldview once all of the
+// datasets have been added.
+type worldview struct {
+	// Country information by Natural Earth ADM0_A3 code
+	countries map[string]Location
+
+	// Features that the point of view assigns to a different country
+	claims []claim
+}
+
+// claim is a feature assigned to a different country by the point of view.
+type claim struct {
+	shape   s2.Shape
+	country string
+}
+
+// addWorldview records the parts of a feature needed to apply the worldview.
+func (r *Rgeo) addWorldview(shape s2.Shape, loc Location, p map[string]interface{}) {
+	if r.pov == nil {
+		r.pov = &worldview{countries: make(map[string]Location)}
+	}
+
+	own := firstNonEmpty(getPropertyString(p, "ADM0_A3"), loc.CountryCode3)
+
+	if _, ok := r.pov.countries[own]; !ok && own != "" && loc.Country != "" {
+		r.pov.countries[own] = loc
+	}
+
+	country := getPropertyString(p, "ADM0_A3_"+r.opts.worldview)
+	if country != "" && country != "-99" && country != own {
+		r.pov.claims = append(r.pov.claims, claim{shape: shape, country: country})
+	}
+}
+
+// applyWorldview moves the claimed features to the countries claiming them.
+// The index is rebuilt with those features first so that they take
+// precedence over the features for the same area as it is on the ground.
+func (r *Rgeo) applyWorldview() {
+	if r.pov == nil {
+		return
+	}
+
+	claimed := make(map[s2.Shape]bool, len(r.pov.claims))
+	index := s2.NewShapeIndex()
+
+	for _, c := range r.pov.claims {
+		country, ok := r.pov.countries[c.country]
+		if !ok {
+			country = Location{CountryCode3: c.country}
+		}
+
+		m := r.locs[c.shape]
+		m.Location = m.Location.withCountry(country)
+		r.locs[c.shape] = m
+
+		claimed[c.shape] = true
+		index.Add(c.shape)
+	}
+
+	for id := 0; id < r.index.Len(); id++ {
+		if shape := r.index.Shape(int32(id)); !claimed[shape] {
+			index.Add(shape)
+		}
+	}
+
+	r.index = index
+	r.pov = nil
+}
+
+// withCountry returns l with the country information replaced by that of c.
+func (l Location) withCountry(c Location) Location {
+	l.Country = c.Country
+	l.CountryLong = c.CountryLong
+	l.CountryCode2 = c.CountryCode2
+	l.CountryCode3 = c.CountryCode3
+	l.Continent = c.Continent
+	l.Region = c.Region
+	l.SubRegion = c.SubRegion
+	l.names = l.names.withCountry(c.names)
+
+	return l
+}
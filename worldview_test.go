@@ -0,0 +1,173 @@
+/*
+Copyright 2020 Sam Smith
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License.  You may obtain a copy of the
+License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied.  See the License for the
+specific language governing permissions and limitations under the License.
+*/
+
+package rgeo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestWithWorldview(t *testing.T) {
+	countries := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ADMIN":"India","ISO_A3":"IND","ADM0_A3":"IND",
+					"ADM0_A3_IN":"IND","ADM0_A3_PK":"IND","NAME_DE":"Indien"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[0,0],[2,0],[2,2],[0,2],[0,0]]]}},
+				{"type":"Feature",
+				"properties":{"ADMIN":"Pakistan","ISO_A3":"PAK","ADM0_A3":"PAK",
+					"ADM0_A3_IN":"PAK","ADM0_A3_PK":"PAK"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[2,0],[4,0],[4,2],[2,2],[2,0]]]}},
+				{"type":"Feature",
+				"properties":{"ADMIN":"Western Sahara","ISO_A3":"ESH","ADM0_A3":"SAH",
+					"ADM0_A3_MA":"MAR","ADM0_A3_IN":"SAH"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[4,0],[6,0],[6,2],[4,2],[4,0]]]}},
+				{"type":"Feature",
+				"properties":{"ADMIN":"Morocco","ISO_A3":"MAR","ADM0_A3":"MAR"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[6,0],[8,0],[8,2],[6,2],[6,0]]]}}
+			]
+		}`
+	disputed := `{
+		"type":"FeatureCollection",
+			"features":[
+				{"type":"Feature",
+				"properties":{"ADMIN":"Pakistan","ADM0_A3":"KAS",
+					"ADM0_A3_IN":"IND","ADM0_A3_PK":"PAK"},
+				"geometry":{"type":"Polygon",
+					"coordinates":[[[2,1],[3,1],[3,2],[2,2],[2,1]]]}}
+			]
+		}`
+
+	india := Location{Country: "India", CountryCode3: "IND"}
+	pakistan := Location{Country: "Pakistan", CountryCode3: "PAK"}
+
+	var testdata = []struct {
+		name      string
+		worldview string
+		in        []float64
+		expected  Location
+	}{
+		{
+			name:     "Kashmir on the ground",
+			in:       []float64{2.5, 1.5},
+			expected: pakistan,
+		},
+		{
+			name:      "Kashmir from India",
+			worldview: "IND",
+			in:        []float64{2.5, 1.5},
+			expected:  india,
+		},
+		{
+			name:      "Kashmir from India, two letter code",
+			worldview: "in",
+			in:        []float64{2.5, 1.5},
+			expected:  india,
+		},
+		{
+			name:      "Kashmir from Pakistan",
+			worldview: "PAK",
+			in:        []float64{2.5, 1.5},
+			expected:  pakistan,
+		},
+		{
+			name:      "Undisputed Pakistan from India",
+			worldview: "IND",
+			in:        []float64{2.5, 0.5},
+			expected:  pakistan,
+		},
+		{
+			name:      "Western Sahara from India",
+			worldview: "IND",
+			in:        []float64{5, 1},
+			expected:  Location{Country: "Western Sahara", CountryCode3: "ESH"},
+		},
+		{
+			name:      "Western Sahara from Morocco",
+			worldview: "MAR",
+			in:        []float64{5, 1},
+			expected:  Location{Country: "Morocco", CountryCode3: "MAR"},
+		},
+	}
+
+	for _, test := range testdata {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			r, err := NewWithOptions(
+				WithReaders(strings.NewReader(countries), strings.NewReader(disputed)),
+				WithWorldview(test.worldview),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loc, err := r.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(test.expected, loc); diff != nil {
+				t.Error(diff)
+			}
+
+			// The order of the features is kept in snapshots
+			var buf bytes.Buffer
+			if err := r.Save(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := Load(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			loc, err = loaded.ReverseGeocode(test.in)
+			if err != nil {
+				t.Error(err)
+			}
+			if diff := deep.Equal(test.expected, loc); diff != nil {
+				t.Error("loaded:", diff)
+			}
+		})
+	}
+
+	t.Run("localized", func(t *testing.T) {
+		r, err := NewWithOptions(
+			WithReaders(strings.NewReader(countries), strings.NewReader(disputed)),
+			WithWorldview("IND"),
+			WithLanguages("de"),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loc, err := r.ReverseGeocode([]float64{2.5, 1.5})
+		if err != nil {
+			t.Error(err)
+		}
+		if c := loc.Localized("de").Country; c != "Indien" {
+			t.Errorf("expected: Indien\n got: %s\n", c)
+		}
+	})
+}